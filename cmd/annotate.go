@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"golang.org/x/mod/modfile"
+)
+
+// annotationPrefix starts every comment added to modfiles by annotateModFiles.
+// It's used to find the comments again so re-running updates them instead of
+// adding duplicates.
+const annotationPrefix = "// gomodcheck:"
+
+// projectModFilePaths returns the paths of the project's modfiles in sorted
+// order.
+func (c modCheckCommand) projectModFilePaths() []string {
+	var res []string
+
+	for modFilePath, depSet := range c.checker.LoadedDeps() {
+		if slices.Contains(c.checker.ProjectDeps(), depSet) {
+			res = append(res, modFilePath)
+		}
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// annotateModFiles adds a comment with the wanted version to each require or
+// replace line in the project's modfiles that caused a mismatch. Comments from
+// previous runs are removed first so lines that no longer mismatch are cleaned
+// up. Mismatches whose effective version was set outside a project modfile,
+// like by a go.work file, aren't annotated.
+func (c modCheckCommand) annotateModFiles(depErrs []depError) error {
+	for _, modFilePath := range c.projectModFilePaths() {
+		// Maps from line number -> annotation for that line.
+		annotations := map[int]string{}
+
+		for _, depErr := range depErrs {
+			if depErr.GotLoc.ReplaceFilePath() != modFilePath {
+				continue
+			}
+
+			annotation := fmt.Sprintf(
+				"%s expected %s",
+				annotationPrefix,
+				depErr.WantVersion,
+			)
+
+			if len(depErr.WantSource) > 0 {
+				annotation += fmt.Sprintf(" (from %s)", depErr.WantSource)
+			}
+
+			annotations[depErr.GotLoc.EffectiveLocation().Row] = annotation
+		}
+
+		if err := annotateModFile(modFilePath, annotations); err != nil {
+			return errors.Wrapf(err, "annotating modfile %s", modFilePath)
+		}
+	}
+
+	return nil
+}
+
+// annotateModFile replaces any existing annotations in the modfile at path
+// with the given ones. The file is only rewritten if its contents change.
+func annotateModFile(path string, annotations map[int]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "reading modfile")
+	}
+
+	f, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return errors.Wrap(err, "parsing modfile")
+	}
+
+	for _, line := range modFileLines(f) {
+		suffix := slices.DeleteFunc(
+			line.Suffix,
+			func(comment modfile.Comment) bool {
+				return strings.HasPrefix(comment.Token, annotationPrefix)
+			},
+		)
+
+		if annotation, ok := annotations[line.Start.Line]; ok {
+			suffix = append(suffix, modfile.Comment{Token: annotation})
+		}
+
+		line.Suffix = suffix
+	}
+
+	res := modfile.Format(f.Syntax)
+	if string(res) == string(data) {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrap(err, "getting modfile info")
+	}
+
+	return errors.Wrap(
+		os.WriteFile(path, res, info.Mode().Perm()),
+		"writing modfile",
+	)
+}
+
+// modFileLines returns every line in the modfile, including those inside
+// blocks.
+func modFileLines(f *modfile.File) []*modfile.Line {
+	var res []*modfile.Line
+
+	for _, stmt := range f.Syntax.Stmt {
+		switch stmt := stmt.(type) {
+		case *modfile.Line:
+			res = append(res, stmt)
+		case *modfile.LineBlock:
+			res = append(res, stmt.Line...)
+		}
+	}
+
+	return res
+}
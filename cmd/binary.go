@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// findBinaryDepErrors compares the module versions embedded in the binary
+// passed to the command against the effective versions in the project module
+// the binary was built from. The project's version is the wanted version since
+// the binary is expected to have been built from it.
+func (c modCheckCommand) findBinaryDepErrors() ([]depError, error) {
+	binaryDeps, err := dependencies.NewProjectDependenciesFromBinary(
+		c.binaryPath,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading binary %s", c.binaryPath)
+	}
+
+	var projectDepSet dependencies.PackageDependencies
+
+	for _, depSet := range c.checker.ProjectDeps() {
+		if depSet.ModulePath() == binaryDeps.ModulePath() {
+			projectDepSet = depSet
+			break
+		}
+	}
+
+	if projectDepSet == nil {
+		return nil, errors.Errorf(
+			"binary %s was built from module %s which isn't a project module",
+			c.binaryPath,
+			binaryDeps.ModulePath(),
+		)
+	}
+
+	// Local replaces in the binary are written as they were in the main
+	// module's modfile.
+	moduleDir := filepath.Dir(projectDepSet.ModFilePath())
+
+	var res []depError
+
+	for _, binaryDep := range binaryDeps.AllDependencies() {
+		depPath := c.checker.CanonicalPath(binaryDep.OriginalVersion().Path)
+
+		projectDep := c.checker.GetProjectDep(projectDepSet, depPath)
+		if projectDep == nil {
+			continue
+		}
+
+		c.checker.MarkCompared(depPath)
+
+		wantVersion := c.checker.ComparableVersion(projectDep)
+		gotVersion := c.checker.ComparableVersionFrom(binaryDep, moduleDir)
+
+		if wantVersion != gotVersion {
+			res = append(
+				res,
+				depError{
+					DepPath:     depPath,
+					GotPath:     binaryDep.OriginalVersion().Path,
+					WantVersion: wantVersion,
+					GotVersion:  gotVersion,
+					GotLoc:      binaryDep.Location(),
+					WantLoc:     projectDep.Location(),
+					WantSource:  "project module " + projectDepSet.ModulePath(),
+				},
+			)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+const (
+	// failOnAny fails the run if any problem is found.
+	failOnAny = "any"
+
+	// failOnNone only fails the run if a critical module mismatches.
+	failOnNone = "none"
+)
+
+// isCritical returns true if the dep at the given path, or the path it's an
+// alias of, was passed as a critical module.
+func (c modCheckCommand) isCritical(depPath string) bool {
+	canonical := c.checker.CanonicalPath(depPath)
+
+	for _, critical := range c.criticalModules {
+		if c.checker.CanonicalPath(critical) == canonical {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mismatchLabel returns the label to start the description of the mismatch
+// with.
+func (c modCheckCommand) mismatchLabel(depErr depError) string {
+	if c.isCritical(depErr.DepPath) {
+		return "Critical module mismatch"
+	}
+
+	return "Module mismatch"
+}
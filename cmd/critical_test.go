@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestFailOn(t *testing.T) {
+	table := []struct {
+		name    string
+		failOn  string
+		wantErr bool
+	}{
+		{
+			name:    "Any",
+			failOn:  failOnAny,
+			wantErr: true,
+		},
+		{
+			name:   "None",
+			failOn: failOnNone,
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GOFLAGS", "")
+			t.Setenv("GOWORK", "off")
+			t.Setenv("GOPROXY", "off")
+
+			chdir(t, filepath.Join("testdata", "unused", "proj"))
+
+			// example.com/unused is never imported so the run has a finding.
+			c := &modCheckCommand{
+				failUnusedDirect: true,
+				failOn:           test.failOn,
+				outputFormat:     outputFormatText,
+			}
+
+			if err := c.newChecker(); err != nil {
+				t.Fatalf("creating checker: %v", err)
+			}
+
+			err := c.check(context.Background(), []string{"./..."})
+
+			if !test.wantErr {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if !isFindingsError(err) {
+				t.Errorf("got error %v, want a findings error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// printIneffectiveReplaces prints a warning for every replace directive in the
+// project's modfiles that never applies. It returns the number of warnings
+// printed.
+func (c modCheckCommand) printIneffectiveReplaces() int {
+	var count int
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		for _, rep := range projectDepSet.IneffectiveReplaces() {
+			c.printIneffectiveReplace(rep)
+			count++
+		}
+	}
+
+	return count
+}
+
+func (c modCheckCommand) printIneffectiveReplace(
+	rep dependencies.IneffectiveReplace,
+) {
+	fmt.Fprintf(
+		os.Stderr,
+		"Ineffective replace: in %s: replace %s => %s never applies: %s\n",
+		c.locationToString(rep.Location, rep.Location.OriginalLocation()),
+		rep.Old,
+		rep.New,
+		rep.Reason,
+	)
+}
+
+// printUnrequiredReplaces prints a warning for every replace directive in the
+// project's modfiles for a module that isn't required. It returns the number
+// of warnings printed.
+func (c modCheckCommand) printUnrequiredReplaces() int {
+	var count int
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		for _, rep := range projectDepSet.UnrequiredReplaces() {
+			fmt.Fprintf(
+				os.Stderr,
+				"Unrequired replace: in %s: replace %s => %s has no matching "+
+					"require\n",
+				c.locationToString(rep.Location, rep.Location.OriginalLocation()),
+				rep.Old,
+				rep.New,
+			)
+
+			count++
+		}
+	}
+
+	return count
+}
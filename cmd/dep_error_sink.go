@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// depErrorSink handles dependency errors as they're found. Errors are printed
+// immediately so large result sets don't have to be held in memory. They're
+// only kept if an output needs the full set at the end of the run.
+type depErrorSink struct {
+	c modCheckCommand
+
+	// text is where errors are printed in text form.
+	text io.Writer
+
+	// keep denotes whether errors should be saved in kept.
+	keep bool
+	kept []depError
+
+	// count is the number of errors that weren't suppressed.
+	count int
+
+	// criticalCount is the number of errors that weren't suppressed and were
+	// for a critical module.
+	criticalCount int
+
+	// suppressionsUsed tracks which of the command's suppressions matched an
+	// error.
+	suppressionsUsed []bool
+
+	// jsonl is where errors are streamed as JSON lines. It's nil if JSON lines
+	// output wasn't requested.
+	jsonl io.Writer
+
+	// err is the first error encountered while streaming errors.
+	err error
+}
+
+func (c modCheckCommand) newDepErrorSink() *depErrorSink {
+	res := &depErrorSink{
+		c:    c,
+		text: os.Stderr,
+		keep: c.annotateGoMod ||
+			c.outputFormat == outputFormatLSP ||
+			c.outputFormat == outputFormatJSON ||
+			len(c.githubSummary()) > 0,
+		suppressionsUsed: make([]bool, len(c.suppressions)),
+	}
+
+	if c.outputFormat == outputFormatJSONL {
+		res.jsonl = os.Stdout
+	}
+
+	return res
+}
+
+func (s *depErrorSink) add(depErr depError) {
+	if s.c.suppress(depErr, s.suppressionsUsed) {
+		return
+	}
+
+	s.count++
+	if s.c.isCritical(depErr.DepPath) {
+		s.criticalCount++
+	}
+
+	s.c.printFormattedErr(s.text, depErr)
+
+	if s.jsonl != nil && s.err == nil {
+		s.err = writeJSONLine(s.jsonl, s.c.newJSONMismatch(depErr))
+	}
+
+	if s.keep {
+		s.kept = append(s.kept, depErr)
+	}
+}
+
+func (s *depErrorSink) addAll(depErrs []depError) {
+	for _, depErr := range depErrs {
+		s.add(depErr)
+	}
+}
+
+// close finishes streaming errors and returns the first error encountered while
+// streaming them.
+func (s *depErrorSink) close() error {
+	if s.jsonl != nil && s.err == nil {
+		s.err = writeJSONLine(
+			s.jsonl,
+			jsonSummary{
+				Type:          jsonRecordTypeSummary,
+				Workspace:     s.c.formatPath(s.c.workFile),
+				FormatVersion: s.c.formatVersion,
+				Mismatches:    s.count,
+				ComparedDeps:  len(s.c.checker.ComparedDeps()),
+			},
+		)
+	}
+
+	return errors.Wrap(s.err, "streaming mismatches")
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// syntheticDepErrors returns count mismatches for deps read from a generated
+// modfile so each has a real location to print.
+func syntheticDepErrors(b *testing.B, count int) []depError {
+	b.Helper()
+
+	var sb strings.Builder
+
+	sb.WriteString("module example.com/proj\n\nrequire (\n")
+
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&sb, "\texample.com/dep%d v1.0.0\n", i)
+	}
+
+	sb.WriteString(")\n")
+
+	deps, err := dependencies.NewProjectDependenciesFromModfileData(
+		nil,
+		"go.mod",
+		[]byte(sb.String()),
+	)
+	if err != nil {
+		b.Fatalf("parsing synthetic modfile: %v", err)
+	}
+
+	res := make([]depError, 0, count)
+
+	for _, dep := range deps.AllDependencies() {
+		res = append(res, depError{
+			DepPath:     dep.OriginalVersion().Path,
+			GotPath:     dep.OriginalVersion().Path,
+			WantVersion: dep.OriginalVersion().Path + "@v1.1.0",
+			GotVersion:  dep.OriginalVersion().String(),
+			GotLoc:      dep.Location(),
+			WantLoc:     dep.Location(),
+			WantSource:  "match-dep source example.com/dep",
+		})
+	}
+
+	return res
+}
+
+// BenchmarkDepErrorSink compares streaming mismatches as they're found against
+// keeping every mismatch until the end of the run. retained-B/op is the growth
+// in live heap memory between creating the sink and closing it, measured after
+// a GC on both sides so only what the sink still references is counted.
+func BenchmarkDepErrorSink(b *testing.B) {
+	depErrs := syntheticDepErrors(b, 10000)
+
+	checker, err := engine.New(engine.Options{})
+	if err != nil {
+		b.Fatalf("creating checker: %v", err)
+	}
+
+	c := modCheckCommand{checker: checker, outputFormat: outputFormatText}
+
+	table := []struct {
+		name string
+		keep bool
+	}{
+		{name: "Stream"},
+		{name: "KeepAll", keep: true},
+	}
+
+	for _, test := range table {
+		b.Run(test.name, func(b *testing.B) {
+			var (
+				retained      int64
+				before, after runtime.MemStats
+			)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				b.StartTimer()
+
+				sink := c.newDepErrorSink()
+				sink.text = io.Discard
+				sink.keep = test.keep
+
+				sink.addAll(depErrs)
+
+				if err := sink.close(); err != nil {
+					b.Fatalf("closing sink: %v", err)
+				}
+
+				b.StopTimer()
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				runtime.KeepAlive(sink)
+				b.StartTimer()
+
+				retained += int64(after.HeapInuse) - int64(before.HeapInuse)
+			}
+
+			b.ReportMetric(float64(retained)/float64(b.N), "retained-B/op")
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+const dumpTreeFormatYAML = "yaml"
+
+// yamlString quotes the input so it's always parsed as a YAML string. Go's
+// quoting rules for printable ASCII are compatible with YAML double-quoted
+// scalars.
+func yamlString(s string) string {
+	return strconv.Quote(s)
+}
+
+// writeTreeYAML writes the effective dependency versions of each project
+// module as YAML. Modules and dependencies are sorted by path so the output
+// diffs cleanly between runs.
+func writeTreeYAML(
+	w io.Writer,
+	projectDeps []dependencies.PackageDependencies,
+) error {
+	sorted := make([]dependencies.PackageDependencies, len(projectDeps))
+	copy(sorted, projectDeps)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ModulePath() < sorted[j].ModulePath()
+	})
+
+	var sb strings.Builder
+
+	if len(sorted) == 0 {
+		sb.WriteString("modules: []\n")
+	} else {
+		sb.WriteString("modules:\n")
+	}
+
+	for _, depSet := range sorted {
+		fmt.Fprintf(&sb, "  - module: %s\n", yamlString(depSet.ModulePath()))
+		fmt.Fprintf(&sb, "    go: %s\n", yamlString(depSet.GoVersion()))
+
+		deps := depSet.AllDependencies()
+		if len(deps) == 0 {
+			sb.WriteString("    dependencies: []\n")
+			continue
+		}
+
+		sb.WriteString("    dependencies:\n")
+
+		for _, dep := range deps {
+			fmt.Fprintf(
+				&sb,
+				"      - path: %s\n",
+				yamlString(dep.OriginalVersion().Path),
+			)
+			fmt.Fprintf(
+				&sb,
+				"        version: %s\n",
+				yamlString(dep.OriginalVersion().Version),
+			)
+			fmt.Fprintf(
+				&sb,
+				"        effective: %s\n",
+				yamlString(dep.EffectiveVersion().String()),
+			)
+			fmt.Fprintf(&sb, "        direct: %t\n", dep.Direct())
+
+			_, replaced := dep.Location().ReplaceLocation()
+			fmt.Fprintf(&sb, "        replaced: %t\n", replaced)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return errors.WithStack(err)
+}
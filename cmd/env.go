@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	matchDepEnvVar     = "GOMODCHECK_MATCH_DEP"
+	matchReplaceEnvVar = "GOMODCHECK_MATCH_REPLACES"
+)
+
+// splitEnvList splits a comma or newline separated list, dropping empty
+// entries and surrounding whitespace.
+func splitEnvList(value string) []string {
+	var res []string
+
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); len(field) > 0 {
+			res = append(res, field)
+		}
+	}
+
+	return res
+}
+
+// applyEnvDefaults populates rules from environment variables. Values passed
+// on the command line take precedence, so the environment is only consulted
+// for flags that weren't set.
+func (c *modCheckCommand) applyEnvDefaults(flags *pflag.FlagSet) {
+	if value, ok := os.LookupEnv(matchDepEnvVar); ok &&
+		!flags.Changed(matchDepVarName) {
+		c.rawMatchDeps = splitEnvList(value)
+	}
+
+	if value, ok := os.LookupEnv(matchReplaceEnvVar); ok &&
+		!flags.Changed(matchReplaceVarName) {
+		c.checkReplacePackages = splitEnvList(value)
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ExitCodeFindings is the exit code used when the check ran but found
+	// problems in the project, like dependency mismatches.
+	ExitCodeFindings = 1
+
+	// ExitCodeError is the exit code used when the check couldn't run, like
+	// when a modfile fails to parse or a flag is invalid.
+	ExitCodeError = 2
+)
+
+// findingsError is returned when the check completed but found problems in
+// the project. It lets callers tell problems with the project apart from
+// problems running the check.
+type findingsError struct {
+	msg string
+}
+
+func (e findingsError) Error() string {
+	return e.msg
+}
+
+func findingsErrorf(format string, args ...any) error {
+	return errors.WithStack(findingsError{msg: fmt.Sprintf(format, args...)})
+}
+
+func isFindingsError(err error) bool {
+	var findingsErr findingsError
+	return errors.As(err, &findingsErr)
+}
+
+// ExitCode returns the process exit code for an error returned by Execute. It
+// returns 0 for no error, ExitCodeFindings if the check found problems, and
+// ExitCodeError for everything else.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case isFindingsError(err):
+		return ExitCodeFindings
+	default:
+		return ExitCodeError
+	}
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// checkFindings contains every problem found while checking the project once.
+// It's used by output formats that report all problems at once instead of
+// printing them as they're found.
+type checkFindings struct {
+	depErrs    []depError
+	violations []versionViolation
+	unusedDeps []dependencies.Dependency
+	deepDeps   []dependencies.Dependency
+	drift      []lockDrift
+	changes    []depChange
+
+	missingLocalReplaces []dependencies.Dependency
+	goViolations         []goVersionViolation
+	inconsistencies      []indirectInconsistency
+	overlaps             []replaceTargetOverlap
+	pathMismatches       []modulePathMismatch
+}
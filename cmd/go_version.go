@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/mod/semver"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// goVersionViolation describes a loaded dependency modfile that requires a
+// newer go version than the project supports.
+type goVersionViolation struct {
+	modFilePath string
+	depSet      dependencies.PackageDependencies
+
+	// projectModule is the project module with the oldest go version.
+	projectModule string
+	projectGo     string
+}
+
+// goSemver returns the go version in semantic version form so it can be
+// compared. Returns false if the version can't be compared, like for release
+// candidates.
+func goSemver(goVersion string) (string, bool) {
+	res := "v" + goVersion
+	return res, semver.IsValid(res) && len(semver.Prerelease(res)) == 0
+}
+
+// oldestProjectGoVersion returns the project module with the oldest go
+// version. Every dependency must support it since it may be built with it.
+func (c modCheckCommand) oldestProjectGoVersion() (string, string, bool) {
+	var module, goVersion, oldest string
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		v, ok := goSemver(projectDepSet.GoVersion())
+		if !ok {
+			continue
+		}
+
+		if len(oldest) == 0 || semver.Compare(v, oldest) < 0 {
+			module = projectDepSet.ModulePath()
+			goVersion = projectDepSet.GoVersion()
+			oldest = v
+		}
+	}
+
+	return module, goVersion, len(oldest) > 0
+}
+
+// findGoVersionViolations returns every loaded dependency modfile whose go
+// directive is newer than the oldest go directive in the project's modfiles.
+func (c modCheckCommand) findGoVersionViolations() []goVersionViolation {
+	projectModule, projectGo, ok := c.oldestProjectGoVersion()
+	if !ok {
+		c.logVerbose("skipping go version check: no comparable project go version")
+		return nil
+	}
+
+	projectVersion, _ := goSemver(projectGo)
+
+	var (
+		res      []goVersionViolation
+		modFiles = make([]string, 0, len(c.checker.LoadedDeps()))
+	)
+
+	for modFilePath := range c.checker.LoadedDeps() {
+		modFiles = append(modFiles, modFilePath)
+	}
+
+	sort.Strings(modFiles)
+
+	for _, modFilePath := range modFiles {
+		depSet := c.checker.LoadedDeps()[modFilePath]
+		if slices.Contains(c.checker.ProjectDeps(), depSet) ||
+			len(depSet.GoVersion()) == 0 {
+			continue
+		}
+
+		v, ok := goSemver(depSet.GoVersion())
+		if !ok {
+			c.logVerbose(
+				"skipping go version check for %s: can't compare go version %s",
+				c.formatPath(modFilePath),
+				depSet.GoVersion(),
+			)
+
+			continue
+		}
+
+		if semver.Compare(v, projectVersion) > 0 {
+			res = append(
+				res,
+				goVersionViolation{
+					modFilePath:   modFilePath,
+					depSet:        depSet,
+					projectModule: projectModule,
+					projectGo:     projectGo,
+				},
+			)
+		}
+	}
+
+	return res
+}
+
+func (c modCheckCommand) printGoVersionViolation(
+	violation goVersionViolation,
+) {
+	loc := violation.depSet.GoVersionLocation()
+
+	fmt.Fprintf(
+		os.Stderr,
+		"Go version too new: in modfile %s for module %s line %d, col %d: "+
+			"requires go %s but project module %s requires go %s\n",
+		c.formatPath(violation.modFilePath),
+		violation.depSet.ModulePath(),
+		loc.Row,
+		loc.Col,
+		violation.depSet.GoVersion(),
+		violation.projectModule,
+		violation.projectGo,
+	)
+}
@@ -3,15 +3,18 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slices"
-	"golang.org/x/tools/go/packages"
 
+	"github.com/alcionai/gomodcheck/internal/engine"
 	"github.com/alcionai/gomodcheck/pkg/dependencies"
+	"github.com/alcionai/gomodcheck/pkg/manifest"
 )
 
 type modCheckCommand struct {
@@ -25,323 +28,910 @@ type modCheckCommand struct {
 	// same name in the package this command is run on.
 	rawMatchDeps []string
 
-	// parsedMatchDeps is populated from the info in rawMatchDeps. It goes from
-	// <package path> -> set <dep path> where the package path is the path that
-	// will appear in this package's mod file and the dep path is the package path
-	// of the dependency the matching should be done on.
-	parsedMatchDeps map[string]map[string]struct{}
-
-	// projectDeps contains all dependency sets loaded from gomodfiles in this
-	// project.
-	projectDeps []dependencies.PackageDependencies
-
-	// depDeps contains package path -> dependency sets. The package path is used
-	// instead of the gomodfile path so that we can determine which dependencies
-	// to check.
-	depDeps map[string]dependencies.PackageDependencies
-
-	// allLoadedDeps contains the dependency sets that has been created reading
-	// modfiles. It maps from the path of the gomodfile to the dependencies read
-	// from the gomodfile.
-	allLoadedDeps map[string]dependencies.PackageDependencies
+	// checker loads the project and compares it against the match-dep and
+	// match-replace rules. It's created from the rule flags when they're parsed.
+	checker *engine.Checker
+
+	// rulesReportFormat is the format to output the rules coverage report in.
+	// If empty no report is output.
+	rulesReportFormat string
+
+	// rawAliases contains the unparsed set of <alias path>:<canonical path> to
+	// parse. Deps found under an alias path are treated as the dep with the
+	// canonical path.
+	rawAliases []string
+
+	// rawCanonicalizeRules contains the unparsed set of
+	// <path regex>=<replacement> rules to parse.
+	rawCanonicalizeRules []string
+
+	// verbose enables printing extra information about the run to stderr.
+	verbose bool
+
+	// binaryPath is the path to a compiled go binary whose embedded module
+	// versions should be compared against the project. If empty no binary is
+	// checked.
+	binaryPath string
+
+	// skipPrerelease makes mismatches where either version is a pre-release or
+	// pseudo-version get ignored.
+	skipPrerelease bool
+
+	// sourceDirectOnly restricts match-dep rules to only use the wanted version
+	// of a dep if it's a direct dependency of the package it's sourced from.
+	sourceDirectOnly bool
+
+	// githubSummaryPath is the file to append a markdown summary of the results
+	// to. If empty the path in $GITHUB_STEP_SUMMARY is used if it's set.
+	githubSummaryPath string
+
+	// failUnusedDirect enables reporting direct dependencies in project modfiles
+	// that aren't imported by any loaded package.
+	failUnusedDirect bool
+
+	// traceDepPath is the path of a dep to print details about the comparison
+	// logic for. If empty no trace output is printed.
+	traceDepPath string
+
+	// dumpTreeFormat is the format to output the effective versions of all
+	// project deps in. If empty the tree isn't output.
+	dumpTreeFormat string
+
+	// relativePaths makes output render modfile paths relative to the current
+	// directory instead of as absolute paths.
+	relativePaths bool
+
+	// reportDeadReplaces enables printing warnings about replace directives in
+	// the project's modfiles that never apply.
+	reportDeadReplaces bool
+
+	// reportUnrequiredReplaces enables printing warnings about replace
+	// directives in the project's modfiles for modules that aren't required.
+	reportUnrequiredReplaces bool
+
+	// reportReplaceTargetOverlap enables printing warnings about modules that
+	// are both required and the target of a replace for another module.
+	reportReplaceTargetOverlap bool
+
+	// rawRegexVersionRules contains the unparsed set of
+	// <path regex>@<version constraints> rules to parse.
+	rawRegexVersionRules []string
+
+	// regexVersionRules is populated from the info in rawRegexVersionRules.
+	regexVersionRules []regexVersionRule
+
+	// rawRequiredVersionRules contains the unparsed set of
+	// <dep path>@<version>[,<version>...] rules to parse.
+	rawRequiredVersionRules []string
+
+	// requiredVersionRules is populated from the info in
+	// rawRequiredVersionRules.
+	requiredVersionRules []requiredVersionRule
+
+	// rawMaxVersionRules contains the unparsed set of <dep path>@<version>
+	// ceilings to parse.
+	rawMaxVersionRules []string
+
+	// maxVersionRules is populated from the info in rawMaxVersionRules.
+	maxVersionRules []maxVersionRule
+
+	// workFiles contains the go.work files to check the project with. The
+	// project is checked once with each workspace. If empty the go.work file
+	// used is whatever the go command detects.
+	workFiles []string
+
+	// workFile is the go.work file to use for the current check of the project.
+	// If empty the go.work file is detected by the go command.
+	workFile string
+
+	// compareWorkspace enables comparing the versions each workspace module
+	// declares against the versions the workspace builds with.
+	compareWorkspace bool
+
+	// manifestPath is the path to a dependency manifest from another build
+	// system whose pinned module versions should be compared against the
+	// project. If empty no manifest is checked.
+	manifestPath string
+
+	// manifestFormat is the format of the file at manifestPath.
+	manifestFormat string
+
+	// annotateGoMod enables adding comments with the wanted version next to the
+	// lines in the project's modfiles that caused mismatches.
+	annotateGoMod bool
+
+	// reachableOnly restricts checks to modules that provide a package in the
+	// transitive import graph of the packages the command is run on.
+	reachableOnly bool
+
+	// checkLockPath is the path to a lockfile of effective versions the
+	// project's dependencies must match. If empty no lockfile is checked.
+	checkLockPath string
+
+	// updateLockPath is the path to write a lockfile of the project's effective
+	// versions to. If empty no lockfile is written.
+	updateLockPath string
+
+	// sinceTag is the git tag to compare the effective versions in the
+	// project's modfiles against. If empty no comparison is done.
+	sinceTag string
+
+	// failOnChange causes the command to fail if any dependency changed since
+	// sinceTag instead of only reporting the changes.
+	failOnChange bool
+
+	// localReplacePatterns contains the module paths, or path prefixes ending
+	// in /*, whose project dependencies must be replaced with local paths.
+	localReplacePatterns []string
+
+	// checkGoVersion enables checking that loaded dependency modfiles don't
+	// require a newer go version than the project's modfiles.
+	checkGoVersion bool
+
+	// checkModulePaths enables checking that the path of each project module
+	// matches its directory relative to repoRoot.
+	checkModulePaths bool
+
+	// repoRoot is the directory module directories are compared relative to
+	// when checking module paths.
+	repoRoot string
+
+	// modulePathPrefix is the module path expected for repoRoot. If empty the
+	// path of the module at repoRoot is used.
+	modulePathPrefix string
+
+	// suppressionsPath is the path to a file of accepted mismatches that
+	// shouldn't be reported. If empty no mismatches are suppressed.
+	suppressionsPath string
+
+	// suppressions is populated from the file at suppressionsPath.
+	suppressions []suppression
+
+	// packagesFromPath is the path to a file of package patterns to check in
+	// addition to those passed as arguments. If "-" patterns are read from
+	// stdin.
+	packagesFromPath string
+
+	// listUnusedSuppressions enables printing the suppressions that didn't match
+	// any mismatch.
+	listUnusedSuppressions bool
+
+	// checkIndirectConsistency enables checking that indirect project deps
+	// aren't older than the versions required by the project's direct deps.
+	checkIndirectConsistency bool
+
+	// metricsPath is the path to write Prometheus metrics about the run to. If
+	// empty no metrics are written.
+	metricsPath string
+
+	// criticalModules contains the paths of modules whose mismatches are
+	// labeled as critical in the output. Mismatches of critical modules fail
+	// the run no matter the fail policy.
+	criticalModules []string
+
+	// failOn is the fail policy for every problem other than critical module
+	// mismatches.
+	failOn string
+
+	// outputFormat is the format problems are reported in. Text output is
+	// always printed to stderr while other formats are printed to stdout.
+	outputFormat string
+
+	// formatVersion is the schema version to use for machine-readable output.
+	formatVersion int
+
+	// lspOutput and jsonOutput collect the findings from every check of the
+	// project for the lsp and json output formats so a single report is
+	// printed even if the project is checked once per workspace. They're nil
+	// until a check collects its findings.
+	lspOutput  *lspReport
+	jsonOutput *jsonReport
+
+	// metrics collects the counts from every check of the project for the
+	// metrics file.
+	metrics runMetrics
+
+	// maxReplaceDepth is the maximum number of file locations allowed in the
+	// ancestry chain of any loaded dependency. If 0 the depth isn't checked.
+	maxReplaceDepth int
 }
 
-func (c *modCheckCommand) parseAndVerifyMatchDeps() error {
-	// Split the input into two parts, the package to check the dep version in and
-	// the dep name that we're checking the version of.
-	for _, input := range c.rawMatchDeps {
-		parts := strings.Split(input, ":")
+// logVerbose prints the formatted message to stderr if verbose output was
+// requested.
+func (c modCheckCommand) logVerbose(format string, args ...any) {
+	if !c.verbose {
+		return
+	}
 
-		switch {
-		case len(parts) != 2:
-			return errors.Errorf("unexpected dep match input: %s", input)
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// newChecker creates the checker for the current check of the project from
+// the rule flags.
+func (c *modCheckCommand) newChecker() error {
+	opts := engine.Options{
+		MatchDeps:         c.rawMatchDeps,
+		MatchReplaces:     c.checkReplacePackages,
+		Aliases:           c.rawAliases,
+		CanonicalizeRules: c.rawCanonicalizeRules,
+		WorkFile:          c.workFile,
+		ReachableOnly:     c.reachableOnly,
+		SourceDirectOnly:  c.sourceDirectOnly,
+		SkipPrerelease:    c.skipPrerelease,
+		FormatPath:        c.formatPath,
+		TraceDep:          c.traceDepPath,
+		Trace:             os.Stderr,
+	}
+
+	if c.verbose {
+		opts.Log = os.Stderr
+	}
+
+	checker, err := engine.New(opts)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	c.checker = checker
+
+	return nil
+}
+
+func (c *modCheckCommand) parseAndVerifyFlags() error {
+	// The checker needs to be created first so that other rules can be
+	// validated using canonical paths.
+	if err := c.newChecker(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := c.parseAndVerifyRegexVersionRules(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := c.parseAndVerifyRequiredVersionRules(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := c.parseAndVerifyMaxVersionRules(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := c.verifyManifestFormat(); err != nil {
+		return errors.WithStack(err)
+	}
 
-		case len(parts[0]) == 0, len(parts[1]) == 0:
+	if c.maxReplaceDepth < 0 {
+		return errors.Errorf(
+			"max replace depth must not be negative: %d",
+			c.maxReplaceDepth,
+		)
+	}
+
+	switch c.failOn {
+	case failOnAny, failOnNone:
+	default:
+		return errors.Errorf("unsupported fail policy: %s", c.failOn)
+	}
+
+	switch c.rulesReportFormat {
+	case "", rulesReportFormatJSON:
+	default:
+		return errors.Errorf(
+			"unsupported rules report format: %s",
+			c.rulesReportFormat,
+		)
+	}
+
+	if !slices.Contains(supportedFormatVersions, c.formatVersion) {
+		return errors.Errorf(
+			"unsupported format version %d, latest version is %d",
+			c.formatVersion,
+			latestFormatVersion,
+		)
+	}
+
+	if len(c.checkLockPath) > 0 && len(c.updateLockPath) > 0 {
+		return errors.Errorf(
+			"only one of --%s and --%s can be used",
+			checkLockVarName,
+			updateLockVarName,
+		)
+	}
+
+	if c.failOnChange && len(c.sinceTag) == 0 {
+		return errors.Errorf(
+			"--%s requires --%s",
+			failOnChangeVarName,
+			sinceTagVarName,
+		)
+	}
+
+	if err := c.verifyLocalReplacePatterns(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(c.suppressionsPath) > 0 {
+		if err := c.readSuppressions(); err != nil {
+			return errors.Wrap(err, "reading suppressions")
+		}
+	}
+
+	switch c.outputFormat {
+	case outputFormatText:
+	case outputFormatLSP, outputFormatJSON, outputFormatJSONL:
+		if len(c.rulesReportFormat) > 0 || len(c.dumpTreeFormat) > 0 {
 			return errors.Errorf(
-				"empty package path in dep match input: %s",
-				input,
+				"output format %s can't be used with --%s or --%s",
+				c.outputFormat,
+				rulesReportVarName,
+				dumpTreeVarName,
 			)
 		}
+	default:
+		return errors.Errorf("unsupported output format: %s", c.outputFormat)
+	}
 
-		if c.parsedMatchDeps[parts[0]] == nil {
-			c.parsedMatchDeps[parts[0]] = map[string]struct{}{}
-		}
+	switch c.dumpTreeFormat {
+	case "", dumpTreeFormatYAML:
+	default:
+		return errors.Errorf(
+			"unsupported dependency tree format: %s",
+			c.dumpTreeFormat,
+		)
+	}
 
-		c.parsedMatchDeps[parts[0]][parts[1]] = struct{}{}
+	return nil
+}
+
+// depError is a mismatch found by any of the command's checks.
+type depError = engine.Mismatch
+
+// formatPath returns the path to output for the given modfile path. If relative
+// paths were requested and the path can be made relative to the current
+// directory then the relative path is returned.
+func (c modCheckCommand) formatPath(path string) string {
+	if !c.relativePaths || len(path) == 0 {
+		return path
 	}
 
-	// Make sure that each dep we're checking the version of only appears once.
-	validateTmp := make(map[string]string, len(c.parsedMatchDeps))
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
 
-	for packageName, depSet := range c.parsedMatchDeps {
-		for dep := range depSet {
-			// We've already been asked to check the version of this dep by sourcing
-			// the version from a different package. Return an error.
-			if otherPackageName, ok := validateTmp[dep]; ok {
-				return errors.Errorf(
-					"dep %s being sourced from multiple packages: %s and %s",
-					dep,
-					otherPackageName,
-					packageName,
-				)
-			}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
 
-			validateTmp[dep] = packageName
-		}
+	rel, err := filepath.Rel(wd, absPath)
+	if err != nil {
+		return path
 	}
 
-	return nil
+	return rel
 }
 
-func (c *modCheckCommand) getOrLoadPackageDeps(
-	pkg *packages.Package,
-	dep dependencies.Dependency,
-) (dependencies.PackageDependencies, bool, error) {
-	if pkg.Module == nil {
-		return nil, false, nil
+// locationToString returns a description of where the given file location is.
+func (c modCheckCommand) locationToString(
+	loc dependencies.LocationTree,
+	fileLoc dependencies.FileLocation,
+) string {
+	return dependencies.LocationString(loc, fileLoc, c.formatPath)
+}
+
+// effectiveLocationToString returns a description of where the effective
+// version of the dependency at loc was set. This may be in a different file
+// than the modfile if the dependency was replaced by a go.work file.
+func (c modCheckCommand) effectiveLocationToString(
+	loc dependencies.LocationTree,
+) string {
+	if rep, ok := loc.ReplaceLocation(); ok &&
+		loc.ReplaceFilePath() != loc.ModFilePath() {
+		return fmt.Sprintf(
+			"%s for module %s line %d, col %d",
+			c.formatPath(loc.ReplaceFilePath()),
+			loc.ParentPackage(),
+			rep.Row,
+			rep.Col,
+		)
 	}
 
-	modFilePath := pkg.Module.GoMod
+	return c.locationToString(loc, loc.EffectiveLocation())
+}
 
-	if pkg.Module.Replace != nil {
-		modFilePath = pkg.Module.Replace.GoMod
+// ancestry returns loc followed by each of its ancestors, ending with the
+// location in the project.
+func ancestry(loc dependencies.LocationTree) []dependencies.LocationTree {
+	var res []dependencies.LocationTree
+
+	for loc != nil {
+		res = append(res, loc)
+		loc = loc.Ancestor()
 	}
 
-	// No gomodfile specified, check the next package.
-	if len(modFilePath) == 0 {
-		return nil, false, nil
+	return res
+}
+
+func (c modCheckCommand) ancestryToString(
+	tree dependencies.LocationTree,
+) string {
+	var res string
+
+	for _, loc := range ancestry(tree) {
+		res += "\t\toriginally included in " +
+			c.locationToString(loc, loc.OriginalLocation())
+
+		if rep, ok := loc.ReplaceLocation(); ok {
+			var inFile string
+
+			if loc.ReplaceFilePath() != loc.ModFilePath() {
+				inFile = " in " + c.formatPath(loc.ReplaceFilePath())
+			}
+
+			res += fmt.Sprintf(
+				"\n\t\t\treplaced%s at line %d, col %d",
+				inFile,
+				rep.Row,
+				rep.Col,
+			)
+		}
+
+		res += "\n"
 	}
 
-	// We've already loaded info for this particular gomodfile. No need to load
-	// it again so continue on.
-	if deps := c.allLoadedDeps[modFilePath]; deps != nil {
-		return deps, false, nil
+	return res
+}
+
+func (c modCheckCommand) printFormattedErr(w io.Writer, depErr depError) {
+	msg := fmt.Sprintf(
+		"%s: in %s: have version %s but want version %s",
+		c.mismatchLabel(depErr),
+		c.effectiveLocationToString(depErr.GotLoc),
+		depErr.GotVersion,
+		depErr.WantVersion,
+	)
+
+	if len(depErr.WantSource) > 0 {
+		msg += fmt.Sprintf(" (from %s)", depErr.WantSource)
 	}
 
-	// We actually need to go load data.
-	deps, err := dependencies.NewProjectDependenciesFromModfile(dep, modFilePath)
-	if err != nil {
-		return nil, false, errors.Wrapf(
-			err,
-			"loading dependency info for: %s",
-			modFilePath,
+	msg += "\n"
+
+	if depErr.IncompatibleMigration {
+		msg += incompatibleMigrationHelp(depErr)
+	} else if depErr.GotPath != depErr.DepPath {
+		msg += fmt.Sprintf(
+			"\tdep %s found as alias %s\n",
+			depErr.DepPath,
+			depErr.GotPath,
 		)
 	}
 
-	c.allLoadedDeps[modFilePath] = deps
+	msg += "\tgot version:\n" + c.ancestryToString(depErr.GotLoc)
+	msg += "\twant version:\n" + c.ancestryToString(depErr.WantLoc)
 
-	return deps, true, nil
+	fmt.Fprint(w, msg)
 }
 
-func (c *modCheckCommand) readDepMappings(
+func (c *modCheckCommand) run(
 	ctx context.Context,
-	packagePath string,
+	packagePatterns []string,
 ) error {
-	cfg := &packages.Config{
-		Context: ctx,
-		Mode:    packages.NeedName | packages.NeedImports | packages.NeedModule,
+	var err error
+
+	start := time.Now()
+
+	if len(c.workFiles) > 0 {
+		err = c.checkWorkFiles(ctx, packagePatterns)
+	} else {
+		err = c.check(ctx, packagePatterns)
 	}
 
-	pkgs, err := packages.Load(cfg, packagePath)
-	if err != nil {
-		return errors.Wrap(err, "getting packages")
+	if printErr := c.printReports(os.Stdout); printErr != nil {
+		return errors.WithStack(printErr)
 	}
 
-	for _, pkg := range pkgs {
-		pkgDepSet, freshLoad, err := c.getOrLoadPackageDeps(pkg, nil)
-		if err != nil {
-			return errors.Wrap(err, "loading project deps")
-		} else if freshLoad {
-			c.projectDeps = append(c.projectDeps, pkgDepSet)
+	if len(c.metricsPath) > 0 {
+		if metricsErr := c.writeMetrics(err, time.Since(start)); metricsErr != nil {
+			return errors.Wrap(metricsErr, "writing metrics")
 		}
+	}
 
-		// Go through the imports in this package. If any of them are in the list of
-		// packages that we're going to compare against load them as well.
-		for _, importPkg := range pkg.Imports {
-			var importPkgPath string
+	return err
+}
 
-			if importPkg.Module != nil {
-				importPkgPath = importPkg.Module.Path
+// collectReports adds the findings from the current check of the project to
+// the report for the output format if it reports all problems at once.
+func (c *modCheckCommand) collectReports(findings checkFindings) {
+	switch c.outputFormat {
+	case outputFormatLSP:
+		if c.lspOutput == nil {
+			c.lspOutput = &lspReport{
+				FormatVersion: c.formatVersion,
+				Diagnostics:   map[string][]lspDiagnostic{},
 			}
+		}
 
-			// If the package backing this import isn't one of the ones we're going to
-			// check against then don't bother loading it.
-			if _, ok := c.parsedMatchDeps[importPkgPath]; !ok &&
-				!slices.Contains(c.checkReplacePackages, importPkgPath) {
-				continue
-			}
+		c.lspOutput.merge(c.buildLSPReport(findings))
 
-			// Pull the dep info on this package that we've already parsed from the
-			// current gomodfile. This allows us to build a full lineage of file
-			// locations.
-			importDep := pkgDepSet.GetDep(importPkgPath)
-
-			if deps, freshLoad, err := c.getOrLoadPackageDeps(
-				importPkg,
-				importDep,
-			); err != nil {
-				return errors.Wrapf(
-					err,
-					"loading deps for dependency %s",
-					importPkgPath,
-				)
-			} else if freshLoad {
-				c.depDeps[importPkgPath] = deps
-			}
+	case outputFormatJSON:
+		if c.jsonOutput == nil {
+			report := c.newJSONReport()
+			c.jsonOutput = &report
+		}
+
+		c.jsonOutput.merge(c.buildJSONReport(findings))
+	}
+}
+
+// printReports writes the reports collected across every check of the project
+// to w. Nothing is written if no check got far enough to collect findings.
+func (c modCheckCommand) printReports(w io.Writer) error {
+	if c.lspOutput != nil {
+		if err := printLSPReport(w, *c.lspOutput); err != nil {
+			return errors.Wrap(err, "printing LSP diagnostics")
+		}
+	}
+
+	if c.jsonOutput != nil {
+		if err := printJSONReport(w, *c.jsonOutput); err != nil {
+			return errors.Wrap(err, "printing JSON report")
 		}
 	}
 
 	return nil
 }
 
-type depError struct {
-	wantVersion string
-	gotVersion  string
+func (c *modCheckCommand) check(
+	ctx context.Context,
+	packagePatterns []string,
+) error {
+	var depErrSink *depErrorSink
 
-	gotLoc  dependencies.LocationTree
-	wantLoc dependencies.LocationTree
-}
+	// Counts are recorded even if the check fails part way so the metrics
+	// cover everything that was checked.
+	defer func() { c.metrics.record(c.checker, depErrSink) }()
+
+	if err := c.checker.Load(ctx, packagePatterns...); err != nil {
+		return errors.Wrap(err, "reading dependency mappings")
+	}
+
+	c.logUnhandledDirectives()
+
+	if len(c.dumpTreeFormat) > 0 {
+		if err := writeTreeYAML(os.Stdout, c.checker.ProjectDeps()); err != nil {
+			return errors.Wrap(err, "printing dependency tree")
+		}
+	}
+
+	depErrSink = c.newDepErrorSink()
+
+	if err := c.checker.Compare(depErrSink.add); err != nil {
+		return errors.Wrap(err, "checking dependencies")
+	}
+
+	if c.compareWorkspace {
+		if err := c.findWorkspaceDivergences(depErrSink.add); err != nil {
+			return errors.Wrap(err, "comparing workspace modules")
+		}
+	}
+
+	if len(c.binaryPath) > 0 {
+		binaryErrs, err := c.findBinaryDepErrors()
+		if err != nil {
+			return errors.Wrap(err, "checking binary")
+		}
+
+		depErrSink.addAll(binaryErrs)
+	}
+
+	if len(c.manifestPath) > 0 {
+		manifestErrs, err := c.findManifestDepErrors()
+		if err != nil {
+			return errors.Wrap(err, "checking external manifest")
+		}
+
+		depErrSink.addAll(manifestErrs)
+	}
+
+	if err := depErrSink.close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if c.listUnusedSuppressions {
+		for _, s := range c.unusedSuppressions(depErrSink.suppressionsUsed) {
+			c.printUnusedSuppression(s)
+		}
+	}
+
+	if len(c.checker.ComparedDeps()) == 0 {
+		c.logVerbose("no checkable dependencies matched")
+	} else {
+		c.logVerbose("checked %d dependencies", len(c.checker.ComparedDeps()))
+	}
 
-func (c modCheckCommand) findDepErrors() []depError {
-	var (
-		res []depError
+	depErrs := depErrSink.kept
 
-		// Maps from package path -> dependencies.Dependency that needs to be
-		// compared to the dependencies.Dependency in the main project.
-		depsToCheck = map[string]dependencies.Dependency{}
+	if c.annotateGoMod {
+		if err := c.annotateModFiles(depErrs); err != nil {
+			return errors.Wrap(err, "annotating modfiles")
+		}
+	}
+
+	violations := append(
+		c.findVersionRegexViolations(),
+		c.findRequiredVersionViolations()...,
 	)
+	violations = append(violations, c.findMaxVersionViolations()...)
 
-	for depPackage, matchDepSet := range c.parsedMatchDeps {
-		depSet := c.depDeps[depPackage]
-		if depSet == nil {
-			// There either wasn't a gomodfile for this dep or the dep wasn't used by
-			// an import of the main project.
-			continue
+	for _, violation := range violations {
+		c.printVersionViolation(violation)
+	}
+
+	if c.reportDeadReplaces {
+		if c.printIneffectiveReplaces() == 0 {
+			c.logVerbose("no ineffective replace directives found")
 		}
+	}
 
-		for depPath := range matchDepSet {
-			if dep := depSet.GetDep(depPath); dep != nil {
-				depsToCheck[depPath] = dep
-			}
+	if c.reportUnrequiredReplaces {
+		if c.printUnrequiredReplaces() == 0 {
+			c.logVerbose("no unrequired replace directives found")
+		}
+	}
+
+	var overlaps []replaceTargetOverlap
+
+	if c.reportReplaceTargetOverlap {
+		overlaps = c.findReplaceTargetOverlaps()
+
+		for _, overlap := range overlaps {
+			c.printReplaceTargetOverlap(overlap)
 		}
 	}
 
-	for _, depPackage := range c.checkReplacePackages {
-		depSet := c.depDeps[depPackage]
-		if depSet == nil {
-			continue
+	if err := c.writeGithubSummary(depErrs); err != nil {
+		return errors.Wrap(err, "writing GitHub job summary")
+	}
+
+	var unusedDeps []dependencies.Dependency
+
+	if c.failUnusedDirect {
+		var err error
+
+		unusedDeps, err = c.findUnusedDirectDeps(ctx, packagePatterns)
+		if err != nil {
+			return errors.Wrap(err, "finding unused direct dependencies")
 		}
 
-		for _, dep := range depSet.Replacements() {
-			// TODO(ashmrtn): Make sure some other package doesn't also require this
-			// dep be checked. We need to check this because we don't know upfront
-			// what replace directives deps will have.
-			depsToCheck[dep.OriginalVersion().Path] = dep
+		for _, dep := range unusedDeps {
+			c.printUnusedDep(dep)
 		}
 	}
 
-	for _, checkDep := range depsToCheck {
-		for _, projectDepSet := range c.projectDeps {
-			projectDep := projectDepSet.GetDep(checkDep.OriginalVersion().Path)
-			if projectDep == nil {
-				continue
-			}
+	var drift []lockDrift
 
-			wantVersion := checkDep.EffectiveVersion().String()
-			gotVersion := projectDep.EffectiveVersion().String()
-
-			if wantVersion != gotVersion {
-				res = append(
-					res,
-					depError{
-						wantVersion: wantVersion,
-						gotVersion:  gotVersion,
-						gotLoc:      projectDep.Location(),
-						wantLoc:     checkDep.Location(),
-					},
-				)
-			}
+	if len(c.checkLockPath) > 0 {
+		var err error
+
+		drift, err = c.findLockDrift()
+		if err != nil {
+			return errors.Wrap(err, "checking lockfile")
+		}
+
+		for _, d := range drift {
+			c.printLockDrift(d)
 		}
 	}
 
-	return res
-}
+	if len(c.updateLockPath) > 0 {
+		if err := c.writeLockFile(); err != nil {
+			return errors.Wrap(err, "updating lockfile")
+		}
+	}
 
-func ancestryToString(loc dependencies.LocationTree) string {
-	var res string
+	var changes []depChange
 
-	for loc != nil {
-		res += fmt.Sprintf(
-			"\t\toriginally included in modfile for module %s line %d, col %d",
-			loc.ParentPackage(),
-			loc.OriginalLocation().Row,
-			loc.OriginalLocation().Col,
-		)
+	if len(c.sinceTag) > 0 {
+		var err error
 
-		if loc.EffectiveLocation() != loc.OriginalLocation() {
-			res += fmt.Sprintf(
-				"\n\t\t\treplaced at line %d, col %d",
-				loc.EffectiveLocation().Row,
-				loc.EffectiveLocation().Col,
-			)
+		changes, err = c.findChangesSinceTag(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "comparing against %s", c.sinceTag)
 		}
 
-		res += "\n"
+		for _, change := range changes {
+			c.printDepChange(change)
+		}
+	}
 
-		loc = loc.Ancestor()
+	var missingLocalReplaces []dependencies.Dependency
+
+	if len(c.localReplacePatterns) > 0 {
+		missingLocalReplaces = c.findMissingLocalReplaces()
+
+		for _, dep := range missingLocalReplaces {
+			c.printMissingLocalReplace(dep)
+		}
 	}
 
-	return res
-}
+	var goViolations []goVersionViolation
 
-func printFormattedErr(depErr depError) {
-	msg := fmt.Sprintf(
-		"Module mismatch: in modfile for module %s line %d, col %d: "+
-			"have version %s but want version %s\n",
-		depErr.gotLoc.ParentPackage(),
-		depErr.gotLoc.EffectiveLocation().Row,
-		depErr.gotLoc.EffectiveLocation().Col,
-		depErr.gotVersion,
-		depErr.wantVersion,
-	)
+	if c.checkGoVersion {
+		goViolations = c.findGoVersionViolations()
+
+		for _, violation := range goViolations {
+			c.printGoVersionViolation(violation)
+		}
+	}
 
-	msg += "\tgot version:\n" + ancestryToString(depErr.gotLoc)
-	msg += "\twant version:\n" + ancestryToString(depErr.wantLoc)
+	var inconsistencies []indirectInconsistency
 
-	fmt.Fprint(os.Stderr, msg)
-}
+	if c.checkIndirectConsistency {
+		var err error
 
-func (c *modCheckCommand) run(ctx context.Context, packagePath string) error {
-	if err := c.readDepMappings(ctx, packagePath); err != nil {
-		return errors.Wrap(err, "reading dependency mappings")
+		inconsistencies, err = c.findIndirectInconsistencies()
+		if err != nil {
+			return errors.Wrap(err, "checking indirect dependencies")
+		}
+
+		for _, inconsistency := range inconsistencies {
+			c.printIndirectInconsistency(inconsistency)
+		}
 	}
 
-	depErrs := c.findDepErrors()
+	var pathMismatches []modulePathMismatch
+
+	if c.checkModulePaths {
+		var err error
 
-	for _, depErr := range depErrs {
-		printFormattedErr(depErr)
+		pathMismatches, err = c.findModulePathMismatches()
+		if err != nil {
+			return errors.Wrap(err, "checking module paths")
+		}
+
+		for _, mismatch := range pathMismatches {
+			c.printModulePathMismatch(mismatch)
+		}
 	}
 
-	if len(depErrs) > 0 {
-		return errors.New("found dependency mismatches")
+	var deepDeps []dependencies.Dependency
+
+	if c.maxReplaceDepth > 0 {
+		deepDeps = c.findDeepLineageDeps()
+
+		for _, dep := range deepDeps {
+			c.printDeepLineageDep(dep)
+		}
+	}
+
+	c.collectReports(checkFindings{
+		depErrs:    depErrs,
+		violations: violations,
+		unusedDeps: unusedDeps,
+		deepDeps:   deepDeps,
+		drift:      drift,
+		changes:    changes,
+
+		missingLocalReplaces: missingLocalReplaces,
+		goViolations:         goViolations,
+		inconsistencies:      inconsistencies,
+		overlaps:             overlaps,
+		pathMismatches:       pathMismatches,
+	})
+
+	if len(c.rulesReportFormat) > 0 {
+		if err := c.printRulesReport(os.Stdout); err != nil {
+			return errors.Wrap(err, "printing rules report")
+		}
+	}
+
+	if depErrSink.criticalCount > 0 {
+		return findingsErrorf("found dependency mismatches in critical modules")
+	}
+
+	if c.failOn == failOnNone {
+		return nil
+	}
+
+	if depErrSink.count > 0 {
+		return findingsErrorf("found dependency mismatches")
+	}
+
+	if len(unusedDeps) > 0 {
+		return findingsErrorf("found unused direct dependencies")
+	}
+
+	if len(violations) > 0 {
+		return findingsErrorf("found version policy violations")
+	}
+
+	if len(deepDeps) > 0 {
+		return findingsErrorf("found replace lineage exceeding max depth")
+	}
+
+	if len(drift) > 0 {
+		return findingsErrorf("found dependencies that differ from the lockfile")
+	}
+
+	if c.failOnChange && len(changes) > 0 {
+		return findingsErrorf("found dependencies changed since %s", c.sinceTag)
+	}
+
+	if len(missingLocalReplaces) > 0 {
+		return findingsErrorf("found dependencies missing local replaces")
+	}
+
+	if len(goViolations) > 0 {
+		return findingsErrorf("found dependencies requiring a newer go version")
+	}
+
+	if len(inconsistencies) > 0 {
+		return findingsErrorf("found inconsistent indirect dependencies")
+	}
+
+	if len(pathMismatches) > 0 {
+		return findingsErrorf("found module paths not matching their directories")
 	}
 
 	return nil
 }
 
 const (
-	matchReplaceVarName = "match-replaces"
-	matchDepVarName     = "match-dep"
+	matchReplaceVarName     = "match-replaces"
+	matchDepVarName         = "match-dep"
+	rulesReportVarName      = "rules-report"
+	aliasVarName            = "alias"
+	canonicalizeVarName     = "canonicalize"
+	verboseVarName          = "verbose"
+	binaryVarName           = "binary"
+	sourceDirectVarName     = "source-direct-only"
+	githubSummaryVarName    = "github-summary"
+	failUnusedVarName       = "fail-unused-direct"
+	traceDepVarName         = "trace-dep"
+	dumpTreeVarName         = "dump-tree"
+	relativePathsVarName    = "relative-paths"
+	deadReplacesVarName     = "report-dead-replaces"
+	versionRegexVarName     = "require-version-regex"
+	requireVersionVarName   = "require-version"
+	maxVersionVarName       = "max-version"
+	metricsFileVarName      = "metrics-file"
+	skipPrereleaseVarName   = "skip-prerelease"
+	replaceOverlapVarName   = "report-replace-target-overlap"
+	workFileVarName         = "workfile"
+	workspaceVarName        = "workspace"
+	manifestVarName         = "external-manifest"
+	manifestFormatVarName   = "manifest-format"
+	maxReplaceDepthVarName  = "max-replace-depth"
+	annotateGoModVarName    = "annotate-gomod"
+	reachableOnlyVarName    = "reachable-only"
+	formatVersionVarName    = "format-version"
+	checkLockVarName        = "check-against-lock"
+	sinceTagVarName         = "since-tag"
+	failOnChangeVarName     = "fail-on-change"
+	updateLockVarName       = "update-lock"
+	outputVarName           = "output"
+	formatVarName           = "format"
+	criticalModuleVarName   = "critical-module"
+	localReplaceVarName     = "require-local-replace"
+	checkGoVersionVarName   = "check-go-version"
+	checkModulePathsVarName = "check-module-path-matches-dir"
+	repoRootVarName         = "repo-root"
+	modulePathPrefixVarName = "module-path-prefix"
+	suppressionsVarName     = "suppressions"
+	packagesFromVarName     = "packages-from"
+	unusedSuppressVarName   = "list-unused-suppressions"
+	indirectVarName         = "check-indirect-consistency"
+	unrequiredVarName       = "report-unrequired-replaces"
+	compareWorkspaceVarName = "compare-workspace-vs-modules"
+	failOnVarName           = "fail-on"
 )
 
 func newModCheckCommand() *cobra.Command {
 	// Create the struct that's going to do everything so we can use it's
 	// variables as the location to place flag values.
-	runCommand := &modCheckCommand{
-		parsedMatchDeps: map[string]map[string]struct{}{},
-		depDeps:         map[string]dependencies.PackageDependencies{},
-		allLoadedDeps:   map[string]dependencies.PackageDependencies{},
-	}
+	runCommand := &modCheckCommand{}
 
 	// Setup cobra command struct.
 	res := &cobra.Command{
@@ -351,21 +941,30 @@ func newModCheckCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
-			if len(args) != 1 {
-				return errors.Errorf("invalid required package specifier: %s", args)
-			}
+			runCommand.applyEnvDefaults(cmd.Flags())
 
-			if err := runCommand.parseAndVerifyMatchDeps(); err != nil {
+			if err := runCommand.parseAndVerifyFlags(); err != nil {
 				return errors.Wrap(err, "parsing flags")
 			}
 
+			packagePatterns, err := runCommand.packagePatterns(args)
+			if err != nil {
+				return errors.Wrap(err, "getting package patterns")
+			}
+
 			// Don't print usage info after this point since flags have been verified.
 			cmd.SilenceUsage = true
 
-			return runCommand.run(ctx, args[0])
+			return runCommand.run(ctx, packagePatterns)
 		},
+		// Package patterns are passed as args so they must not be mistaken for
+		// unknown subcommands.
+		Args: cobra.ArbitraryArgs,
 	}
 
+	res.CompletionOptions.DisableDefaultCmd = true
+	res.AddCommand(newJSONSchemaCommand())
+
 	// Add flags to the cobra command.
 	flags := res.Flags()
 	flags.StringSliceVar(
@@ -380,6 +979,286 @@ func newModCheckCommand() *cobra.Command {
 		nil,
 		"",
 	)
+	flags.StringVar(
+		&runCommand.rulesReportFormat,
+		rulesReportVarName,
+		"",
+		"output a report of which match rules were used (supported: json)",
+	)
+	flags.StringSliceVar(
+		&runCommand.rawAliases,
+		aliasVarName,
+		nil,
+		"treat <alias path>:<canonical path> as the same dependency",
+	)
+	flags.StringArrayVar(
+		&runCommand.rawCanonicalizeRules,
+		canonicalizeVarName,
+		nil,
+		"rewrite dep paths matching <path regex>=<replacement> before comparing "+
+			"them; rules are applied in order",
+	)
+	flags.BoolVar(
+		&runCommand.verbose,
+		verboseVarName,
+		false,
+		"print extra information about the run to stderr",
+	)
+	flags.StringVar(
+		&runCommand.binaryPath,
+		binaryVarName,
+		"",
+		"compare the module versions embedded in a go binary to the project",
+	)
+	flags.StringVar(
+		&runCommand.manifestPath,
+		manifestVarName,
+		"",
+		"compare the module versions pinned in another build system's manifest "+
+			"to the project",
+	)
+	flags.StringVar(
+		&runCommand.manifestFormat,
+		manifestFormatVarName,
+		manifest.FormatBazel,
+		"format of the external manifest",
+	)
+	flags.IntVar(
+		&runCommand.maxReplaceDepth,
+		maxReplaceDepthVarName,
+		0,
+		"fail if a dep's lineage has more than this many file locations",
+	)
+	flags.BoolVar(
+		&runCommand.annotateGoMod,
+		annotateGoModVarName,
+		false,
+		"add comments with the wanted version to mismatched modfile lines",
+	)
+	flags.BoolVar(
+		&runCommand.reachableOnly,
+		reachableOnlyVarName,
+		false,
+		"only check deps imported by the given packages or their dependencies",
+	)
+	flags.IntVar(
+		&runCommand.formatVersion,
+		formatVersionVarName,
+		latestFormatVersion,
+		"schema version to use for machine-readable output",
+	)
+	flags.StringVar(
+		&runCommand.checkLockPath,
+		checkLockVarName,
+		"",
+		"fail if effective versions differ from those in the given lockfile",
+	)
+	flags.StringVar(
+		&runCommand.updateLockPath,
+		updateLockVarName,
+		"",
+		"write the project's effective versions to the given lockfile",
+	)
+	flags.StringVar(
+		&runCommand.sinceTag,
+		sinceTagVarName,
+		"",
+		"report deps whose effective version changed since the given git tag",
+	)
+	flags.BoolVar(
+		&runCommand.failOnChange,
+		failOnChangeVarName,
+		false,
+		"fail if any dep changed since the --since-tag tag",
+	)
+	flags.StringVar(
+		&runCommand.outputFormat,
+		outputVarName,
+		outputFormatText,
+		"format to report problems in (supported: text, json, jsonl, lsp)",
+	)
+	// Share the value so that the alias and flag set the same format.
+	flags.Var(
+		flags.Lookup(outputVarName).Value,
+		formatVarName,
+		"alias for --"+outputVarName,
+	)
+	flags.StringSliceVar(
+		&runCommand.criticalModules,
+		criticalModuleVarName,
+		nil,
+		"label mismatches of the given module as critical",
+	)
+	flags.StringVar(
+		&runCommand.failOn,
+		failOnVarName,
+		failOnAny,
+		"problems that fail the run besides critical module mismatches "+
+			"(supported: any, none)",
+	)
+	flags.StringVar(
+		&runCommand.metricsPath,
+		metricsFileVarName,
+		"",
+		"write Prometheus metrics about the run to the given file",
+	)
+	flags.StringArrayVar(
+		&runCommand.localReplacePatterns,
+		localReplaceVarName,
+		nil,
+		"require deps matching the module path or <prefix>/* to be replaced "+
+			"with a local path",
+	)
+	flags.BoolVar(
+		&runCommand.checkGoVersion,
+		checkGoVersionVarName,
+		false,
+		"fail if a loaded dep modfile requires a newer go version than the "+
+			"project",
+	)
+	flags.BoolVar(
+		&runCommand.checkModulePaths,
+		checkModulePathsVarName,
+		false,
+		"fail if a project module's path doesn't match its directory",
+	)
+	flags.StringVar(
+		&runCommand.repoRoot,
+		repoRootVarName,
+		".",
+		"directory module directories are relative to when checking module "+
+			"paths",
+	)
+	flags.StringVar(
+		&runCommand.modulePathPrefix,
+		modulePathPrefixVarName,
+		"",
+		"module path expected for the repo root when checking module paths; "+
+			"defaults to the path of the module at the repo root",
+	)
+	flags.StringVar(
+		&runCommand.suppressionsPath,
+		suppressionsVarName,
+		"",
+		"file of <dep path> <want version> <got version> mismatches to ignore",
+	)
+	flags.StringVar(
+		&runCommand.packagesFromPath,
+		packagesFromVarName,
+		"",
+		"file of package patterns to check, one per line; use - for stdin",
+	)
+	flags.BoolVar(
+		&runCommand.listUnusedSuppressions,
+		unusedSuppressVarName,
+		false,
+		"print suppressions that didn't match any mismatch",
+	)
+	flags.BoolVar(
+		&runCommand.checkIndirectConsistency,
+		indirectVarName,
+		false,
+		"fail if an indirect dep is older than a direct dep requires",
+	)
+	flags.BoolVar(
+		&runCommand.reportUnrequiredReplaces,
+		unrequiredVarName,
+		false,
+		"warn about replace directives for modules that aren't required",
+	)
+	flags.BoolVar(
+		&runCommand.reportReplaceTargetOverlap,
+		replaceOverlapVarName,
+		false,
+		"warn about required modules that are also a replace target",
+	)
+	flags.BoolVar(
+		&runCommand.compareWorkspace,
+		compareWorkspaceVarName,
+		false,
+		"fail if a workspace module's versions differ from the workspace's",
+	)
+	flags.BoolVar(
+		&runCommand.skipPrerelease,
+		skipPrereleaseVarName,
+		false,
+		"ignore mismatches involving pre-release or pseudo-versions",
+	)
+	flags.BoolVar(
+		&runCommand.sourceDirectOnly,
+		sourceDirectVarName,
+		false,
+		"only source match-dep versions from direct dependencies",
+	)
+	flags.StringVar(
+		&runCommand.githubSummaryPath,
+		githubSummaryVarName,
+		"",
+		"file to append a markdown summary to (default $"+
+			githubSummaryEnvVar+")",
+	)
+	flags.BoolVar(
+		&runCommand.failUnusedDirect,
+		failUnusedVarName,
+		false,
+		"fail if a direct dependency isn't imported by any loaded package",
+	)
+	flags.StringVar(
+		&runCommand.traceDepPath,
+		traceDepVarName,
+		"",
+		"print details about how the given dep's versions are compared",
+	)
+	flags.StringVar(
+		&runCommand.dumpTreeFormat,
+		dumpTreeVarName,
+		"",
+		"output the effective versions of all project deps (supported: yaml)",
+	)
+	flags.BoolVar(
+		&runCommand.relativePaths,
+		relativePathsVarName,
+		false,
+		"output modfile paths relative to the current directory",
+	)
+	flags.BoolVar(
+		&runCommand.reportDeadReplaces,
+		deadReplacesVarName,
+		false,
+		"warn about replace directives that never apply",
+	)
+	flags.StringArrayVar(
+		&runCommand.rawRegexVersionRules,
+		versionRegexVarName,
+		nil,
+		"require deps matching <path regex>@<version constraints> to satisfy "+
+			"the constraints; a version regex matches the start of the version",
+	)
+	flags.StringArrayVar(
+		&runCommand.rawRequiredVersionRules,
+		requireVersionVarName,
+		nil,
+		"require <dep path>@<version>[,<version>...] to have one of the versions",
+	)
+	flags.StringArrayVar(
+		&runCommand.rawMaxVersionRules,
+		maxVersionVarName,
+		nil,
+		"require <dep path>@<version> to have a version below the given one",
+	)
+	flags.StringArrayVar(
+		&runCommand.workFiles,
+		workFileVarName,
+		nil,
+		"check the project using the given go.work file instead of the "+
+			"detected one; repeat to check multiple workspaces",
+	)
+	// Share the value so that the alias and flag append to the same list.
+	flags.Var(
+		flags.Lookup(workFileVarName).Value,
+		workspaceVarName,
+		"alias for --"+workFileVarName,
+	)
 
 	return res
 }
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+)
+
+// incompatibleMigrationHelp explains why the +incompatible and module forms of
+// a dependency don't match.
+func incompatibleMigrationHelp(depErr depError) string {
+	incompatiblePath, modulePath := depErr.GotPath, depErr.DepPath
+	if len(modulePath) < len(incompatiblePath) {
+		incompatiblePath, modulePath = modulePath, incompatiblePath
+	}
+
+	return fmt.Sprintf(
+		"\t%s added a go.mod file at this major version and moved to module "+
+			"path %s. Versions marked %s predate the go.mod file, so the go "+
+			"command treats the two paths as different modules. Both sides should "+
+			"require %s with imports updated to match.\n",
+		incompatiblePath,
+		modulePath,
+		engine.IncompatibleSuffix,
+		modulePath,
+	)
+}
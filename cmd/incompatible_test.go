@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIncompatibleMigrationHelp(t *testing.T) {
+	table := []struct {
+		name   string
+		depErr depError
+	}{
+		{
+			name: "RuleWantsModule",
+			depErr: depError{
+				DepPath: "example.com/foo/v2",
+				GotPath: "example.com/foo",
+			},
+		},
+		{
+			name: "RuleWantsIncompatible",
+			depErr: depError{
+				DepPath: "example.com/foo",
+				GotPath: "example.com/foo/v2",
+			},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			help := incompatibleMigrationHelp(test.depErr)
+
+			want := "example.com/foo added a go.mod file at this major version " +
+				"and moved to module path example.com/foo/v2."
+			if !strings.Contains(help, want) {
+				t.Errorf("got help %q, want it to contain %q", help, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// indirectInconsistency describes an indirect project dependency whose version
+// is older than the version a direct dependency of the project requires.
+type indirectInconsistency struct {
+	dep         dependencies.Dependency
+	requirer    string
+	requiredDep dependencies.Dependency
+}
+
+// findIndirectInconsistencies compares the version of every indirect project
+// dependency against the versions required by the modfiles of the project's
+// direct dependencies. MVS selects the highest required version so the
+// project's version must be at least as high as every requirement. Replaced
+// dependencies and versions that aren't semantic versions are skipped.
+func (c modCheckCommand) findIndirectInconsistencies() (
+	[]indirectInconsistency,
+	error,
+) {
+	var res []indirectInconsistency
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		for _, direct := range projectDepSet.AllDependencies() {
+			if !direct.Direct() {
+				continue
+			}
+
+			requirerPath := direct.OriginalVersion().Path
+
+			modFilePath, ok := c.checker.ModuleModFile(requirerPath)
+			if !ok {
+				c.logVerbose(
+					"skipping indirect consistency check for requirements of %s: "+
+						"no loaded package imports it",
+					requirerPath,
+				)
+
+				continue
+			}
+
+			requirerDeps, err := dependencies.NewProjectDependenciesFromModfile(
+				direct,
+				modFilePath,
+			)
+			if err != nil {
+				return nil, errors.Wrapf(
+					err,
+					"loading requirements of %s",
+					requirerPath,
+				)
+			}
+
+			for _, required := range requirerDeps.AllDependencies() {
+				dep := projectDepSet.GetDep(required.OriginalVersion().Path)
+				if dep == nil || dep.Direct() ||
+					dep.OriginalVersion() != dep.EffectiveVersion() {
+					continue
+				}
+
+				have := dep.OriginalVersion().Version
+				want := required.OriginalVersion().Version
+
+				if !semver.IsValid(have) || !semver.IsValid(want) {
+					continue
+				}
+
+				if semver.Compare(have, want) < 0 {
+					res = append(
+						res,
+						indirectInconsistency{
+							dep:         dep,
+							requirer:    requirerPath,
+							requiredDep: required,
+						},
+					)
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (c modCheckCommand) printIndirectInconsistency(
+	inconsistency indirectInconsistency,
+) {
+	loc := inconsistency.dep.Location()
+
+	fmt.Fprintf(
+		os.Stderr,
+		"Inconsistent indirect dependency: in %s: have version %s but direct "+
+			"dependency %s requires %s\n\tgot version:\n%s\trequired by:\n%s",
+		c.locationToString(loc, loc.OriginalLocation()),
+		inconsistency.dep.OriginalVersion(),
+		inconsistency.requirer,
+		inconsistency.requiredDep.OriginalVersion(),
+		c.ancestryToString(loc),
+		c.ancestryToString(inconsistency.requiredDep.Location()),
+	)
+}
@@ -0,0 +1,500 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+const (
+	outputFormatJSON  = "json"
+	outputFormatJSONL = "jsonl"
+
+	jsonRecordTypeMismatch = "mismatch"
+	jsonRecordTypeSummary  = "summary"
+)
+
+// jsonLocation is where a version was set in a file. Lines and columns are
+// 1-based.
+type jsonLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// jsonAncestor is one step in the chain of modfiles that led to a version
+// being set, starting with the modfile that set it and ending with the
+// project's modfile.
+type jsonAncestor struct {
+	// Module is the module whose modfile included the dep. It's empty for
+	// external manifests.
+	Module string `json:"module,omitempty"`
+
+	// Original is where the dep was required.
+	Original jsonLocation `json:"original"`
+
+	// Replace is where the dep was replaced if it was.
+	Replace *jsonLocation `json:"replace,omitempty"`
+}
+
+// jsonMismatch is the machine-readable form of a dependency error.
+type jsonMismatch struct {
+	Type string `json:"type"`
+
+	// Workspace is the go.work file the project was checked with if it was
+	// checked once per --workfile.
+	Workspace string `json:"workspace,omitempty"`
+
+	// Dep is the canonical path of the dep.
+	Dep string `json:"dep"`
+
+	// FoundAs is the path the dep was found under in the project if it differs
+	// from Dep.
+	FoundAs string `json:"foundAs,omitempty"`
+
+	WantVersion string `json:"wantVersion"`
+	GotVersion  string `json:"gotVersion"`
+
+	WantSource string       `json:"wantSource,omitempty"`
+	Critical   bool         `json:"critical"`
+	Location   jsonLocation `json:"location"`
+
+	// GotLoc and WantLoc are the full ancestry of the project's version and the
+	// wanted version respectively.
+	GotLoc  []jsonAncestor `json:"gotLoc"`
+	WantLoc []jsonAncestor `json:"wantLoc"`
+}
+
+// jsonDepFinding is the machine-readable form of a problem with a single dep
+// found by one of the checks other than the version comparison.
+type jsonDepFinding struct {
+	// Workspace is the go.work file the project was checked with if it was
+	// checked once per --workfile.
+	Workspace string `json:"workspace,omitempty"`
+
+	// Module is the project module whose dep has the problem if the check
+	// tracks it.
+	Module string `json:"module,omitempty"`
+
+	Dep         string `json:"dep"`
+	WantVersion string `json:"wantVersion,omitempty"`
+	GotVersion  string `json:"gotVersion,omitempty"`
+
+	// TagVersion is the version of the dep at the --since-tag tag.
+	TagVersion string `json:"tagVersion,omitempty"`
+
+	// Rule is the version policy rule that was violated.
+	Rule string `json:"rule,omitempty"`
+
+	// RequiredBy is the direct dependency requiring WantVersion of the dep.
+	RequiredBy string `json:"requiredBy,omitempty"`
+
+	// Location is where the dep was set. It's empty for deps that are no
+	// longer required.
+	Location *jsonLocation `json:"location,omitempty"`
+}
+
+// jsonGoVersionViolation is the machine-readable form of a go version
+// violation.
+type jsonGoVersionViolation struct {
+	Workspace        string       `json:"workspace,omitempty"`
+	Module           string       `json:"module"`
+	GoVersion        string       `json:"goVersion"`
+	ProjectModule    string       `json:"projectModule"`
+	ProjectGoVersion string       `json:"projectGoVersion"`
+	Location         jsonLocation `json:"location"`
+}
+
+// jsonPathMismatch is the machine-readable form of a module path mismatch.
+type jsonPathMismatch struct {
+	Workspace    string       `json:"workspace,omitempty"`
+	Module       string       `json:"module"`
+	ExpectedPath string       `json:"expectedPath"`
+	Location     jsonLocation `json:"location"`
+}
+
+// jsonReplaceTargetOverlap is the machine-readable form of a required module
+// that's also the replacement of another module.
+type jsonReplaceTargetOverlap struct {
+	Workspace string `json:"workspace,omitempty"`
+	Dep       string `json:"dep"`
+
+	// ReplacementFor is the path of the module replaced with Dep.
+	ReplacementFor string       `json:"replacementFor"`
+	Location       jsonLocation `json:"location"`
+}
+
+// jsonReplace is the machine-readable form of a replace directive that
+// doesn't affect the build.
+type jsonReplace struct {
+	Workspace string `json:"workspace,omitempty"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+
+	// Reason explains why the replace directive doesn't affect the build.
+	Reason   string       `json:"reason"`
+	Location jsonLocation `json:"location"`
+}
+
+// jsonReport is the single JSON object output for the json format. If the
+// project is checked once per --workfile the findings from every workspace
+// are combined into one report.
+type jsonReport struct {
+	FormatVersion int            `json:"formatVersion"`
+	Mismatches    []jsonMismatch `json:"mismatches"`
+
+	PolicyViolations     []jsonDepFinding `json:"policyViolations"`
+	UnusedDeps           []jsonDepFinding `json:"unusedDeps"`
+	DeepReplaces         []jsonDepFinding `json:"deepReplaces"`
+	LockDrift            []jsonDepFinding `json:"lockDrift"`
+	Changes              []jsonDepFinding `json:"changes"`
+	MissingLocalReplaces []jsonDepFinding `json:"missingLocalReplaces"`
+
+	IndirectInconsistencies []jsonDepFinding `json:"indirectInconsistencies"`
+
+	GoVersionViolations []jsonGoVersionViolation `json:"goVersionViolations"`
+	PathMismatches      []jsonPathMismatch       `json:"pathMismatches"`
+
+	Overlaps           []jsonReplaceTargetOverlap `json:"overlaps"`
+	DeadReplaces       []jsonReplace              `json:"deadReplaces"`
+	UnrequiredReplaces []jsonReplace              `json:"unrequiredReplaces"`
+
+	ComparedDeps int `json:"comparedDeps"`
+}
+
+// jsonSummary closes a stream of JSON records.
+type jsonSummary struct {
+	Type          string `json:"type"`
+	Workspace     string `json:"workspace,omitempty"`
+	FormatVersion int    `json:"formatVersion"`
+	Mismatches    int    `json:"mismatches"`
+	ComparedDeps  int    `json:"comparedDeps"`
+}
+
+func (c modCheckCommand) newJSONMismatch(depErr depError) jsonMismatch {
+	res := jsonMismatch{
+		Type:        jsonRecordTypeMismatch,
+		Workspace:   c.formatPath(c.workFile),
+		Dep:         depErr.DepPath,
+		WantVersion: depErr.WantVersion,
+		GotVersion:  depErr.GotVersion,
+		WantSource:  depErr.WantSource,
+		Critical:    c.isCritical(depErr.DepPath),
+		Location: jsonLocation{
+			File:   c.formatPath(depErr.GotLoc.ReplaceFilePath()),
+			Line:   depErr.GotLoc.EffectiveLocation().Row,
+			Column: depErr.GotLoc.EffectiveLocation().Col,
+		},
+		GotLoc:  c.jsonAncestry(depErr.GotLoc),
+		WantLoc: c.jsonAncestry(depErr.WantLoc),
+	}
+
+	if depErr.GotPath != depErr.DepPath {
+		res.FoundAs = depErr.GotPath
+	}
+
+	return res
+}
+
+// jsonAncestry converts the location and its ancestors to their
+// machine-readable form.
+func (c modCheckCommand) jsonAncestry(
+	tree dependencies.LocationTree,
+) []jsonAncestor {
+	chain := ancestry(tree)
+	res := make([]jsonAncestor, 0, len(chain))
+
+	for _, loc := range chain {
+		ancestor := jsonAncestor{
+			Module: loc.ParentPackage(),
+			Original: jsonLocation{
+				File:   c.formatPath(loc.ModFilePath()),
+				Line:   loc.OriginalLocation().Row,
+				Column: loc.OriginalLocation().Col,
+			},
+		}
+
+		if rep, ok := loc.ReplaceLocation(); ok {
+			ancestor.Replace = &jsonLocation{
+				File:   c.formatPath(loc.ReplaceFilePath()),
+				Line:   rep.Row,
+				Column: rep.Col,
+			}
+		}
+
+		res = append(res, ancestor)
+	}
+
+	return res
+}
+
+// newJSONLocation returns the machine-readable form of loc in the file at
+// filePath.
+func (c modCheckCommand) newJSONLocation(
+	filePath string,
+	loc dependencies.FileLocation,
+) jsonLocation {
+	return jsonLocation{
+		File:   c.formatPath(filePath),
+		Line:   loc.Row,
+		Column: loc.Col,
+	}
+}
+
+// newJSONDepFinding returns a finding for dep located where its effective
+// version was set. dep may be nil if it's no longer required.
+func (c modCheckCommand) newJSONDepFinding(
+	depPath string,
+	dep dependencies.Dependency,
+) jsonDepFinding {
+	res := jsonDepFinding{
+		Workspace: c.formatPath(c.workFile),
+		Dep:       depPath,
+	}
+
+	if dep != nil {
+		loc := c.newJSONLocation(
+			dep.Location().ReplaceFilePath(),
+			dep.Location().EffectiveLocation(),
+		)
+
+		res.GotVersion = dep.EffectiveVersion().String()
+		res.Location = &loc
+	}
+
+	return res
+}
+
+// newJSONRequireFinding returns a finding for dep located at its require
+// directive.
+func (c modCheckCommand) newJSONRequireFinding(
+	dep dependencies.Dependency,
+) jsonDepFinding {
+	loc := c.newJSONLocation(
+		dep.Location().ModFilePath(),
+		dep.Location().OriginalLocation(),
+	)
+
+	return jsonDepFinding{
+		Workspace:  c.formatPath(c.workFile),
+		Dep:        dep.OriginalVersion().Path,
+		GotVersion: dep.OriginalVersion().Version,
+		Location:   &loc,
+	}
+}
+
+// newJSONReport returns an empty report. Every list is non-nil so they're
+// output as empty arrays instead of null.
+func (c modCheckCommand) newJSONReport() jsonReport {
+	return jsonReport{
+		FormatVersion:           c.formatVersion,
+		Mismatches:              []jsonMismatch{},
+		PolicyViolations:        []jsonDepFinding{},
+		UnusedDeps:              []jsonDepFinding{},
+		DeepReplaces:            []jsonDepFinding{},
+		LockDrift:               []jsonDepFinding{},
+		Changes:                 []jsonDepFinding{},
+		MissingLocalReplaces:    []jsonDepFinding{},
+		IndirectInconsistencies: []jsonDepFinding{},
+		GoVersionViolations:     []jsonGoVersionViolation{},
+		PathMismatches:          []jsonPathMismatch{},
+		Overlaps:                []jsonReplaceTargetOverlap{},
+		DeadReplaces:            []jsonReplace{},
+		UnrequiredReplaces:      []jsonReplace{},
+	}
+}
+
+// buildJSONReport returns the machine-readable form of the findings from the
+// current check of the project.
+func (c modCheckCommand) buildJSONReport(findings checkFindings) jsonReport {
+	res := c.newJSONReport()
+	res.ComparedDeps = len(c.checker.ComparedDeps())
+
+	for _, depErr := range findings.depErrs {
+		res.Mismatches = append(res.Mismatches, c.newJSONMismatch(depErr))
+	}
+
+	for _, violation := range findings.violations {
+		finding := c.newJSONDepFinding(
+			violation.dep.OriginalVersion().Path,
+			violation.dep,
+		)
+		finding.WantVersion = violation.want
+		finding.Rule = violation.rule
+
+		res.PolicyViolations = append(res.PolicyViolations, finding)
+	}
+
+	for _, dep := range findings.unusedDeps {
+		res.UnusedDeps = append(res.UnusedDeps, c.newJSONRequireFinding(dep))
+	}
+
+	for _, dep := range findings.deepDeps {
+		res.DeepReplaces = append(
+			res.DeepReplaces,
+			c.newJSONDepFinding(dep.OriginalVersion().Path, dep),
+		)
+	}
+
+	for _, drift := range findings.drift {
+		finding := c.newJSONDepFinding(drift.depPath, drift.dep)
+		finding.Module = drift.module
+		finding.WantVersion = drift.lockVersion
+
+		res.LockDrift = append(res.LockDrift, finding)
+	}
+
+	for _, change := range findings.changes {
+		finding := c.newJSONDepFinding(change.depPath, change.dep)
+		finding.Module = change.module
+		finding.TagVersion = change.tagVersion
+
+		res.Changes = append(res.Changes, finding)
+	}
+
+	for _, dep := range findings.missingLocalReplaces {
+		finding := c.newJSONRequireFinding(dep)
+		finding.GotVersion = dep.EffectiveVersion().String()
+
+		res.MissingLocalReplaces = append(res.MissingLocalReplaces, finding)
+	}
+
+	for _, inconsistency := range findings.inconsistencies {
+		finding := c.newJSONRequireFinding(inconsistency.dep)
+		finding.WantVersion = inconsistency.requiredDep.OriginalVersion().Version
+		finding.RequiredBy = inconsistency.requirer
+
+		res.IndirectInconsistencies = append(
+			res.IndirectInconsistencies,
+			finding,
+		)
+	}
+
+	for _, violation := range findings.goViolations {
+		res.GoVersionViolations = append(
+			res.GoVersionViolations,
+			jsonGoVersionViolation{
+				Workspace:        c.formatPath(c.workFile),
+				Module:           violation.depSet.ModulePath(),
+				GoVersion:        violation.depSet.GoVersion(),
+				ProjectModule:    violation.projectModule,
+				ProjectGoVersion: violation.projectGo,
+				Location: c.newJSONLocation(
+					violation.modFilePath,
+					violation.depSet.GoVersionLocation(),
+				),
+			},
+		)
+	}
+
+	for _, mismatch := range findings.pathMismatches {
+		res.PathMismatches = append(
+			res.PathMismatches,
+			jsonPathMismatch{
+				Workspace:    c.formatPath(c.workFile),
+				Module:       mismatch.depSet.ModulePath(),
+				ExpectedPath: mismatch.expected,
+				Location: c.newJSONLocation(
+					mismatch.depSet.ModFilePath(),
+					mismatch.depSet.ModuleLocation(),
+				),
+			},
+		)
+	}
+
+	for _, overlap := range findings.overlaps {
+		res.Overlaps = append(res.Overlaps, jsonReplaceTargetOverlap{
+			Workspace:      c.formatPath(c.workFile),
+			Dep:            overlap.required.OriginalVersion().Path,
+			ReplacementFor: overlap.replaced.OriginalVersion().Path,
+			Location: c.newJSONLocation(
+				overlap.required.Location().ModFilePath(),
+				overlap.required.Location().OriginalLocation(),
+			),
+		})
+	}
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		if c.reportDeadReplaces {
+			for _, rep := range projectDepSet.IneffectiveReplaces() {
+				res.DeadReplaces = append(res.DeadReplaces, c.newJSONReplace(rep))
+			}
+		}
+
+		if c.reportUnrequiredReplaces {
+			for _, rep := range projectDepSet.UnrequiredReplaces() {
+				res.UnrequiredReplaces = append(
+					res.UnrequiredReplaces,
+					c.newJSONReplace(rep),
+				)
+			}
+		}
+	}
+
+	return res
+}
+
+// newJSONReplace returns the machine-readable form of a replace directive that
+// doesn't affect the build.
+func (c modCheckCommand) newJSONReplace(
+	rep dependencies.IneffectiveReplace,
+) jsonReplace {
+	return jsonReplace{
+		Workspace: c.formatPath(c.workFile),
+		Old:       rep.Old.String(),
+		New:       rep.New.String(),
+		Reason:    rep.Reason,
+		Location: c.newJSONLocation(
+			rep.Location.ModFilePath(),
+			rep.Location.OriginalLocation(),
+		),
+	}
+}
+
+// merge adds the findings in other to r.
+func (r *jsonReport) merge(other jsonReport) {
+	r.Mismatches = append(r.Mismatches, other.Mismatches...)
+	r.PolicyViolations = append(r.PolicyViolations, other.PolicyViolations...)
+	r.UnusedDeps = append(r.UnusedDeps, other.UnusedDeps...)
+	r.DeepReplaces = append(r.DeepReplaces, other.DeepReplaces...)
+	r.LockDrift = append(r.LockDrift, other.LockDrift...)
+	r.Changes = append(r.Changes, other.Changes...)
+	r.MissingLocalReplaces = append(
+		r.MissingLocalReplaces,
+		other.MissingLocalReplaces...,
+	)
+	r.IndirectInconsistencies = append(
+		r.IndirectInconsistencies,
+		other.IndirectInconsistencies...,
+	)
+	r.GoVersionViolations = append(
+		r.GoVersionViolations,
+		other.GoVersionViolations...,
+	)
+	r.PathMismatches = append(r.PathMismatches, other.PathMismatches...)
+	r.Overlaps = append(r.Overlaps, other.Overlaps...)
+	r.DeadReplaces = append(r.DeadReplaces, other.DeadReplaces...)
+	r.UnrequiredReplaces = append(
+		r.UnrequiredReplaces,
+		other.UnrequiredReplaces...,
+	)
+	r.ComparedDeps += other.ComparedDeps
+}
+
+// printJSONReport writes the findings from every check of the project to w as
+// a single JSON object.
+func printJSONReport(w io.Writer, report jsonReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return errors.WithStack(enc.Encode(report))
+}
+
+// writeJSONLine writes v as a single line of JSON. Each line is written with a
+// single call to w so consumers can process it as soon as it's written.
+func writeJSONLine(w io.Writer, v any) error {
+	return errors.WithStack(json.NewEncoder(w).Encode(v))
+}
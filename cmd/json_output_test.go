@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+func TestPrintReportsCombinesWorkspaces(t *testing.T) {
+	depSet, err := dependencies.NewProjectDependenciesFromModfileData(
+		nil,
+		"go.mod",
+		[]byte("module example.com/wrong\n\nrequire example.com/foo v1.0.0\n"),
+	)
+	if err != nil {
+		t.Fatalf("parsing modfile: %v", err)
+	}
+
+	dep := depSet.GetDep("example.com/foo")
+
+	checker, err := engine.New(engine.Options{})
+	if err != nil {
+		t.Fatalf("creating checker: %v", err)
+	}
+
+	for _, format := range []string{outputFormatJSON, outputFormatLSP} {
+		t.Run(format, func(t *testing.T) {
+			c := modCheckCommand{checker: checker, outputFormat: format}
+
+			for _, workFile := range []string{"go.work", "go.work.ci"} {
+				c.workFile = workFile
+
+				c.collectReports(checkFindings{
+					depErrs: []depError{
+						{
+							DepPath:     "example.com/foo",
+							GotPath:     "example.com/foo",
+							WantVersion: "example.com/foo@v1.1.0",
+							GotVersion:  "example.com/foo@v1.0.0",
+							GotLoc:      dep.Location(),
+							WantLoc:     dep.Location(),
+						},
+					},
+					unusedDeps: []dependencies.Dependency{dep},
+					pathMismatches: []modulePathMismatch{
+						{depSet: depSet, expected: "example.com/proj"},
+					},
+				})
+			}
+
+			var out bytes.Buffer
+
+			if err := c.printReports(&out); err != nil {
+				t.Fatalf("printing reports: %v", err)
+			}
+
+			dec := json.NewDecoder(&out)
+
+			if format == outputFormatLSP {
+				var report lspReport
+
+				if err := dec.Decode(&report); err != nil {
+					t.Fatalf("decoding report: %v", err)
+				}
+
+				// The same modfile was checked with both workspaces so its
+				// diagnostics are only reported once.
+				if got := len(report.Diagnostics[fileURI("go.mod")]); got != 3 {
+					t.Errorf("got %d diagnostics, want 3: %+v", got, report)
+				}
+			} else {
+				var report jsonReport
+
+				if err := dec.Decode(&report); err != nil {
+					t.Fatalf("decoding report: %v", err)
+				}
+
+				if len(report.Mismatches) != 2 ||
+					report.Mismatches[0].Workspace != "go.work" ||
+					report.Mismatches[1].Workspace != "go.work.ci" {
+					t.Errorf(
+						"got mismatches %+v, want one per workspace",
+						report.Mismatches,
+					)
+				}
+
+				if len(report.UnusedDeps) != 2 || len(report.PathMismatches) != 2 {
+					t.Errorf(
+						"got %d unused deps and %d path mismatches, want 2 of each",
+						len(report.UnusedDeps),
+						len(report.PathMismatches),
+					)
+				}
+			}
+
+			if dec.More() {
+				t.Error("more than one report printed")
+			}
+		})
+	}
+}
+
+func TestBuildJSONReportOverlap(t *testing.T) {
+	depSet, err := dependencies.NewProjectDependenciesFromModfileData(
+		nil,
+		"go.mod",
+		[]byte(`module example.com/proj
+
+require (
+	example.com/bar v1.0.0
+	example.com/foo v1.0.0
+)
+
+replace example.com/foo => example.com/bar v1.0.0
+`),
+	)
+	if err != nil {
+		t.Fatalf("parsing modfile: %v", err)
+	}
+
+	checker, err := engine.New(engine.Options{})
+	if err != nil {
+		t.Fatalf("creating checker: %v", err)
+	}
+
+	c := modCheckCommand{checker: checker}
+
+	report := c.buildJSONReport(checkFindings{
+		overlaps: []replaceTargetOverlap{
+			{
+				required: depSet.GetDep("example.com/bar"),
+				replaced: depSet.GetDep("example.com/foo"),
+			},
+		},
+	})
+
+	want := jsonReplaceTargetOverlap{
+		Dep:            "example.com/bar",
+		ReplacementFor: "example.com/foo",
+		Location:       jsonLocation{File: "go.mod", Line: 4, Column: 2},
+	}
+
+	if len(report.Overlaps) != 1 || report.Overlaps[0] != want {
+		t.Errorf("got overlaps %+v, want [%+v]", report.Overlaps, want)
+	}
+
+	// The other replace warnings weren't enabled.
+	if report.DeadReplaces == nil || report.UnrequiredReplaces == nil {
+		t.Error("got nil replace warnings, want empty arrays")
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+func newJSONSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:    "json-schema",
+		Short:  "print the JSON Schema of the --output json report",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return printJSONSchema(cmd.OutOrStdout())
+		},
+	}
+}
+
+// printJSONSchema writes the JSON Schema of the report printed by the json
+// output format to w.
+func printJSONSchema(w io.Writer) error {
+	schema, err := jsonSchemaFor(reflect.TypeOf(jsonReport{}))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = "gomodcheck report"
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return errors.WithStack(enc.Encode(schema))
+}
+
+// jsonSchemaFor returns the schema of the JSON encoding of values of type t.
+// It's generated from the struct fields and their json tags so it stays in
+// sync with the report. Only the kinds used by the report are supported.
+func jsonSchemaFor(t reflect.Type) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonSchemaFor(t.Elem())
+
+	case reflect.Slice:
+		items, err := jsonSchemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"type": "array", "items": items}, nil
+
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+
+	case reflect.Int:
+		return map[string]any{"type": "integer"}, nil
+
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+
+			if len(name) == 0 {
+				name = field.Name
+			}
+
+			property, err := jsonSchemaFor(field.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "field %s", field.Name)
+			}
+
+			properties[name] = property
+
+			if opts != "omitempty" {
+				required = append(required, name)
+			}
+		}
+
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}, nil
+	}
+
+	return nil, errors.Errorf("no JSON Schema for type %s", t)
+}
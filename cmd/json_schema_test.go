@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// checkAgainstSchema reports every way the decoded JSON value doesn't match
+// the subset of JSON Schema output by jsonSchemaFor.
+func checkAgainstSchema(
+	t *testing.T,
+	path string,
+	schema map[string]any,
+	value any,
+) {
+	t.Helper()
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			t.Errorf("%s: got %T, want an object", path, value)
+			return
+		}
+
+		properties := schema["properties"].(map[string]any)
+
+		for _, name := range schema["required"].([]any) {
+			if _, ok := obj[name.(string)]; !ok {
+				t.Errorf("%s: missing required property %s", path, name)
+			}
+		}
+
+		for name, v := range obj {
+			property, ok := properties[name]
+			if !ok {
+				t.Errorf("%s: property %s isn't in the schema", path, name)
+				continue
+			}
+
+			checkAgainstSchema(t, path+"."+name, property.(map[string]any), v)
+		}
+
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			t.Errorf("%s: got %T, want an array", path, value)
+			return
+		}
+
+		for _, v := range arr {
+			checkAgainstSchema(t, path+"[]", schema["items"].(map[string]any), v)
+		}
+
+	case "string":
+		if _, ok := value.(string); !ok {
+			t.Errorf("%s: got %T, want a string", path, value)
+		}
+
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int(n)) {
+			t.Errorf("%s: got %v, want an integer", path, value)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			t.Errorf("%s: got %T, want a boolean", path, value)
+		}
+
+	default:
+		t.Errorf("%s: unknown schema type %v", path, schema["type"])
+	}
+}
+
+func TestJSONSchemaMatchesReport(t *testing.T) {
+	depSet, err := dependencies.NewProjectDependenciesFromModfileData(
+		nil,
+		"go.mod",
+		[]byte(`module example.com/wrong
+
+go 1.21
+
+require (
+	example.com/bar v1.0.0
+	example.com/foo v1.0.0
+)
+
+replace example.com/foo => example.com/bar v1.0.0
+`),
+	)
+	if err != nil {
+		t.Fatalf("parsing modfile: %v", err)
+	}
+
+	bar := depSet.GetDep("example.com/bar")
+	foo := depSet.GetDep("example.com/foo")
+
+	checker, err := engine.New(engine.Options{})
+	if err != nil {
+		t.Fatalf("creating checker: %v", err)
+	}
+
+	c := modCheckCommand{checker: checker, workFile: "go.work"}
+
+	// Every list has an entry so the fields of each entry type are checked.
+	report := c.buildJSONReport(checkFindings{
+		depErrs: []depError{
+			{
+				DepPath:     "example.com/foo",
+				GotPath:     "example.com/other",
+				WantVersion: "example.com/foo@v1.1.0",
+				GotVersion:  "example.com/foo@v1.0.0",
+				WantSource:  "example.com/lib",
+				GotLoc:      foo.Location(),
+				WantLoc:     bar.Location(),
+			},
+		},
+		violations: []versionViolation{
+			{dep: bar, want: "v1.1.0", rule: "example.com/bar@v1.1.0"},
+		},
+		unusedDeps: []dependencies.Dependency{bar},
+		deepDeps:   []dependencies.Dependency{foo},
+		drift: []lockDrift{
+			{module: "example.com/wrong", depPath: "example.com/bar", dep: bar},
+		},
+		changes: []depChange{
+			{module: "example.com/wrong", depPath: "example.com/bar"},
+		},
+		missingLocalReplaces: []dependencies.Dependency{foo},
+		goViolations: []goVersionViolation{
+			{depSet: depSet, modFilePath: "go.mod", projectGo: "1.20"},
+		},
+		inconsistencies: []indirectInconsistency{
+			{dep: bar, requirer: "example.com/foo", requiredDep: bar},
+		},
+		overlaps: []replaceTargetOverlap{{required: bar, replaced: foo}},
+		pathMismatches: []modulePathMismatch{
+			{depSet: depSet, expected: "example.com/proj"},
+		},
+	})
+	report.DeadReplaces = append(report.DeadReplaces, jsonReplace{})
+	report.UnrequiredReplaces = append(report.UnrequiredReplaces, jsonReplace{})
+
+	var out bytes.Buffer
+
+	if err := printJSONReport(&out, report); err != nil {
+		t.Fatalf("printing report: %v", err)
+	}
+
+	var decodedReport any
+
+	if err := json.Unmarshal(out.Bytes(), &decodedReport); err != nil {
+		t.Fatalf("decoding report: %v", err)
+	}
+
+	out.Reset()
+
+	if err := printJSONSchema(&out); err != nil {
+		t.Fatalf("printing schema: %v", err)
+	}
+
+	var schema map[string]any
+
+	if err := json.Unmarshal(out.Bytes(), &schema); err != nil {
+		t.Fatalf("decoding schema: %v", err)
+	}
+
+	checkAgainstSchema(t, "report", schema, decodedReport)
+
+	for name, list := range decodedReport.(map[string]any) {
+		if arr, ok := list.([]any); ok && len(arr) == 0 {
+			t.Errorf("report.%s is empty so its entries aren't checked", name)
+		}
+	}
+}
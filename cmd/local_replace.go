@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+const localReplaceWildcard = "/*"
+
+func (c modCheckCommand) verifyLocalReplacePatterns() error {
+	for _, pattern := range c.localReplacePatterns {
+		trimmed := strings.TrimSuffix(pattern, localReplaceWildcard)
+
+		if len(trimmed) == 0 || strings.Contains(trimmed, "*") {
+			return errors.Errorf(
+				"unexpected local replace pattern %s, wildcards are only supported "+
+					"as the last path element",
+				pattern,
+			)
+		}
+	}
+
+	return nil
+}
+
+// matchesLocalReplacePattern returns true if the path is covered by any of the
+// local replace patterns. Patterns ending in /* match every path under the
+// prefix while other patterns must match the path exactly.
+func (c modCheckCommand) matchesLocalReplacePattern(path string) bool {
+	for _, pattern := range c.localReplacePatterns {
+		prefix, ok := strings.CutSuffix(pattern, localReplaceWildcard)
+		if !ok {
+			if path == pattern {
+				return true
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findMissingLocalReplaces returns the project dependencies that match a local
+// replace pattern but whose effective version isn't a local path.
+func (c modCheckCommand) findMissingLocalReplaces() []dependencies.Dependency {
+	var res []dependencies.Dependency
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		for _, dep := range projectDepSet.AllDependencies() {
+			if !c.matchesLocalReplacePattern(dep.OriginalVersion().Path) {
+				continue
+			}
+
+			if !modfile.IsDirectoryPath(dep.EffectiveVersion().Path) {
+				res = append(res, dep)
+			}
+		}
+	}
+
+	return res
+}
+
+func (c modCheckCommand) printMissingLocalReplace(
+	dep dependencies.Dependency,
+) {
+	fmt.Fprintf(
+		os.Stderr,
+		"Missing local replace: in %s: %s has effective version %s but must "+
+			"be replaced with a local path\n",
+		c.locationToString(dep.Location(), dep.Location().OriginalLocation()),
+		dep.OriginalVersion().Path,
+		dep.EffectiveVersion(),
+	)
+}
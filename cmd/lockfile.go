@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// lockModule contains the effective versions of every dependency of one of the
+// project's modules.
+type lockModule struct {
+	// Module is the module path of the project module.
+	Module string `json:"module"`
+
+	// Dependencies maps from dependency path -> effective version of the
+	// dependency in the form path@version, or just the path for local
+	// replacements.
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// lockFile is a saved snapshot of the effective versions of the project's
+// dependencies.
+type lockFile struct {
+	// FormatVersion is the schema version of the file.
+	FormatVersion int `json:"formatVersion"`
+
+	Modules []lockModule `json:"modules"`
+}
+
+func newLockModule(depSet dependencies.PackageDependencies) lockModule {
+	res := lockModule{
+		Module:       depSet.ModulePath(),
+		Dependencies: map[string]string{},
+	}
+
+	for _, dep := range depSet.AllDependencies() {
+		res.Dependencies[dep.OriginalVersion().Path] = dep.EffectiveVersion().
+			String()
+	}
+
+	return res
+}
+
+func (c modCheckCommand) buildLockFile() lockFile {
+	res := lockFile{
+		FormatVersion: c.formatVersion,
+		Modules:       make([]lockModule, 0, len(c.checker.ProjectDeps())),
+	}
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		res.Modules = append(res.Modules, newLockModule(projectDepSet))
+	}
+
+	sort.Slice(res.Modules, func(i, j int) bool {
+		return res.Modules[i].Module < res.Modules[j].Module
+	})
+
+	return res
+}
+
+// writeLockFile saves the effective versions of the project's dependencies to
+// the lockfile path passed to the command.
+func (c modCheckCommand) writeLockFile() error {
+	data, err := json.MarshalIndent(c.buildLockFile(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "serializing lockfile")
+	}
+
+	data = append(data, '\n')
+
+	return errors.Wrap(
+		os.WriteFile(c.updateLockPath, data, 0o644),
+		"writing lockfile",
+	)
+}
+
+func readLockFile(path string) (lockFile, error) {
+	var res lockFile
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return res, errors.Wrap(err, "reading lockfile")
+	}
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return res, errors.Wrap(err, "parsing lockfile")
+	}
+
+	if !slices.Contains(supportedFormatVersions, res.FormatVersion) {
+		return res, errors.Errorf(
+			"unsupported lockfile format version %d",
+			res.FormatVersion,
+		)
+	}
+
+	return res, nil
+}
+
+// lockDrift describes a difference between the project's current dependencies
+// and those saved in the lockfile.
+type lockDrift struct {
+	module  string
+	depPath string
+
+	// dep is the current dependency. It's nil if the dependency is in the
+	// lockfile but no longer required.
+	dep dependencies.Dependency
+
+	// lockVersion is the effective version saved in the lockfile. It's empty if
+	// the dependency isn't in the lockfile.
+	lockVersion string
+}
+
+// findLockDrift compares the effective versions of the project's dependencies
+// against the lockfile passed to the command. Project modules missing from the
+// lockfile have all their dependencies reported.
+func (c modCheckCommand) findLockDrift() ([]lockDrift, error) {
+	lock, err := readLockFile(c.checkLockPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	locked := make(map[string]map[string]string, len(lock.Modules))
+
+	for _, mod := range lock.Modules {
+		locked[mod.Module] = mod.Dependencies
+	}
+
+	var res []lockDrift
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		module := projectDepSet.ModulePath()
+		lockDeps := locked[module]
+
+		for _, dep := range projectDepSet.AllDependencies() {
+			depPath := dep.OriginalVersion().Path
+			lockVersion, ok := lockDeps[depPath]
+
+			if !ok || lockVersion != dep.EffectiveVersion().String() {
+				res = append(
+					res,
+					lockDrift{
+						module:      module,
+						depPath:     depPath,
+						dep:         dep,
+						lockVersion: lockVersion,
+					},
+				)
+			}
+		}
+
+		lockPaths := make([]string, 0, len(lockDeps))
+
+		for depPath := range lockDeps {
+			lockPaths = append(lockPaths, depPath)
+		}
+
+		sort.Strings(lockPaths)
+
+		for _, depPath := range lockPaths {
+			if projectDepSet.GetDep(depPath) != nil {
+				continue
+			}
+
+			res = append(
+				res,
+				lockDrift{
+					module:      module,
+					depPath:     depPath,
+					lockVersion: lockDeps[depPath],
+				},
+			)
+		}
+	}
+
+	return res, nil
+}
+
+func (c modCheckCommand) printLockDrift(drift lockDrift) {
+	switch {
+	case drift.dep == nil:
+		fmt.Fprintf(
+			os.Stderr,
+			"Lockfile drift: module %s no longer requires %s pinned at %s\n",
+			drift.module,
+			drift.depPath,
+			drift.lockVersion,
+		)
+
+	case len(drift.lockVersion) == 0:
+		fmt.Fprintf(
+			os.Stderr,
+			"Lockfile drift: in %s: %s is required but not in the lockfile\n",
+			c.effectiveLocationToString(drift.dep.Location()),
+			drift.depPath,
+		)
+
+	default:
+		fmt.Fprintf(
+			os.Stderr,
+			"Lockfile drift: in %s: have version %s but lockfile pins %s\n",
+			c.effectiveLocationToString(drift.dep.Location()),
+			drift.dep.EffectiveVersion(),
+			drift.lockVersion,
+		)
+	}
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+const (
+	outputFormatText = "text"
+	outputFormatLSP  = "lsp"
+
+	lspSource = "gomodcheck"
+
+	// Severity values defined by the LSP spec.
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+)
+
+// lspPosition is a 0-based position in a file as defined by the LSP spec.
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+// lspDiagnostic matches the shape of an LSP Diagnostic.
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Message  string   `json:"message"`
+	Source   string   `json:"source"`
+}
+
+type lspReport struct {
+	// FormatVersion is the schema version of the report.
+	FormatVersion int `json:"formatVersion"`
+
+	// Diagnostics maps from file URI -> diagnostics for that file.
+	Diagnostics map[string][]lspDiagnostic `json:"diagnostics"`
+}
+
+// fileURI returns the file URI for the given path. Relative paths are resolved
+// against the current directory.
+func fileURI(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+
+	return u.String()
+}
+
+// addLSPDiagnostic adds a diagnostic for the line at loc. Locations use 1-based
+// rows and columns while LSP positions are 0-based. The range covers the rest
+// of the line since the end of the directive isn't tracked. Locations without
+// a row, like those from build info, can't be placed in a file and are
+// skipped.
+//
+// Columns count runes while LSP characters count UTF-16 code units, so they're
+// only exact when the line before the column has no characters outside the
+// Basic Multilingual Plane. Only whitespace can come before a directive in a
+// modfile so they aren't converted.
+func (r *lspReport) addLSPDiagnostic(
+	filePath string,
+	loc dependencies.FileLocation,
+	severity int,
+	msg string,
+) {
+	if loc.Row == 0 || len(filePath) == 0 {
+		return
+	}
+
+	uri := fileURI(filePath)
+
+	r.Diagnostics[uri] = append(
+		r.Diagnostics[uri],
+		lspDiagnostic{
+			Range: lspRange{
+				Start: lspPosition{Line: loc.Row - 1, Character: loc.Col - 1},
+				End:   lspPosition{Line: loc.Row, Character: 0},
+			},
+			Severity: severity,
+			Message:  msg,
+			Source:   lspSource,
+		},
+	)
+}
+
+func (c modCheckCommand) buildLSPReport(findings checkFindings) lspReport {
+	res := lspReport{
+		FormatVersion: c.formatVersion,
+		Diagnostics:   map[string][]lspDiagnostic{},
+	}
+
+	for _, depErr := range findings.depErrs {
+		msg := fmt.Sprintf(
+			"%s: have version %s but want version %s",
+			c.mismatchLabel(depErr),
+			depErr.GotVersion,
+			depErr.WantVersion,
+		)
+
+		if len(depErr.WantSource) > 0 {
+			msg += fmt.Sprintf(" (from %s)", depErr.WantSource)
+		}
+
+		res.addLSPDiagnostic(
+			depErr.GotLoc.ReplaceFilePath(),
+			depErr.GotLoc.EffectiveLocation(),
+			lspSeverityError,
+			msg,
+		)
+	}
+
+	for _, violation := range findings.violations {
+		loc := violation.dep.Location()
+
+		res.addLSPDiagnostic(
+			loc.ReplaceFilePath(),
+			loc.EffectiveLocation(),
+			lspSeverityError,
+			fmt.Sprintf(
+				"have version %s but want %s (rule %s)",
+				violation.dep.EffectiveVersion(),
+				violation.want,
+				violation.rule,
+			),
+		)
+	}
+
+	for _, dep := range findings.unusedDeps {
+		res.addLSPDiagnostic(
+			dep.Location().ModFilePath(),
+			dep.Location().OriginalLocation(),
+			lspSeverityError,
+			fmt.Sprintf(
+				"%s is required but never imported",
+				dep.OriginalVersion().Path,
+			),
+		)
+	}
+
+	for _, dep := range findings.deepDeps {
+		res.addLSPDiagnostic(
+			dep.Location().ReplaceFilePath(),
+			dep.Location().EffectiveLocation(),
+			lspSeverityError,
+			fmt.Sprintf(
+				"replace lineage has depth %d but max is %d",
+				lineageDepth(dep.Location()),
+				c.maxReplaceDepth,
+			),
+		)
+	}
+
+	for _, drift := range findings.drift {
+		// Deps that are no longer required don't have a location.
+		if drift.dep == nil {
+			continue
+		}
+
+		msg := fmt.Sprintf("%s is not in the lockfile", drift.depPath)
+
+		if len(drift.lockVersion) > 0 {
+			msg = fmt.Sprintf(
+				"have version %s but lockfile pins %s",
+				drift.dep.EffectiveVersion(),
+				drift.lockVersion,
+			)
+		}
+
+		res.addLSPDiagnostic(
+			drift.dep.Location().ReplaceFilePath(),
+			drift.dep.Location().EffectiveLocation(),
+			lspSeverityError,
+			msg,
+		)
+	}
+
+	for _, dep := range findings.missingLocalReplaces {
+		res.addLSPDiagnostic(
+			dep.Location().ModFilePath(),
+			dep.Location().OriginalLocation(),
+			lspSeverityError,
+			fmt.Sprintf(
+				"%s has effective version %s but must be replaced with a local "+
+					"path",
+				dep.OriginalVersion().Path,
+				dep.EffectiveVersion(),
+			),
+		)
+	}
+
+	for _, violation := range findings.goViolations {
+		res.addLSPDiagnostic(
+			violation.modFilePath,
+			violation.depSet.GoVersionLocation(),
+			lspSeverityError,
+			fmt.Sprintf(
+				"requires go %s but project module %s requires go %s",
+				violation.depSet.GoVersion(),
+				violation.projectModule,
+				violation.projectGo,
+			),
+		)
+	}
+
+	for _, inconsistency := range findings.inconsistencies {
+		loc := inconsistency.dep.Location()
+
+		res.addLSPDiagnostic(
+			loc.ModFilePath(),
+			loc.OriginalLocation(),
+			lspSeverityError,
+			fmt.Sprintf(
+				"have version %s but direct dependency %s requires %s",
+				inconsistency.dep.OriginalVersion(),
+				inconsistency.requirer,
+				inconsistency.requiredDep.OriginalVersion(),
+			),
+		)
+	}
+
+	for _, overlap := range findings.overlaps {
+		loc := overlap.required.Location()
+
+		res.addLSPDiagnostic(
+			loc.ModFilePath(),
+			loc.OriginalLocation(),
+			lspSeverityWarning,
+			fmt.Sprintf(
+				"%s is required and is also the replacement for %s",
+				overlap.required.OriginalVersion().Path,
+				overlap.replaced.OriginalVersion().Path,
+			),
+		)
+	}
+
+	for _, mismatch := range findings.pathMismatches {
+		res.addLSPDiagnostic(
+			mismatch.depSet.ModFilePath(),
+			mismatch.depSet.ModuleLocation(),
+			lspSeverityError,
+			fmt.Sprintf(
+				"module %s but its directory expects %s",
+				mismatch.depSet.ModulePath(),
+				mismatch.expected,
+			),
+		)
+	}
+
+	if c.reportDeadReplaces {
+		for _, projectDepSet := range c.checker.ProjectDeps() {
+			for _, rep := range projectDepSet.IneffectiveReplaces() {
+				res.addLSPDiagnostic(
+					rep.Location.ModFilePath(),
+					rep.Location.OriginalLocation(),
+					lspSeverityWarning,
+					fmt.Sprintf(
+						"replace %s => %s never applies: %s",
+						rep.Old,
+						rep.New,
+						rep.Reason,
+					),
+				)
+			}
+		}
+	}
+
+	if c.reportUnrequiredReplaces {
+		for _, projectDepSet := range c.checker.ProjectDeps() {
+			for _, rep := range projectDepSet.UnrequiredReplaces() {
+				res.addLSPDiagnostic(
+					rep.Location.ModFilePath(),
+					rep.Location.OriginalLocation(),
+					lspSeverityWarning,
+					fmt.Sprintf(
+						"replace %s => %s has no matching require",
+						rep.Old,
+						rep.New,
+					),
+				)
+			}
+		}
+	}
+
+	return res
+}
+
+// merge adds the diagnostics in other to r. Diagnostics r already has are
+// skipped since modfiles shared by several workspaces are checked with each.
+func (r *lspReport) merge(other lspReport) {
+	for uri, diags := range other.Diagnostics {
+		for _, diag := range diags {
+			if !slices.Contains(r.Diagnostics[uri], diag) {
+				r.Diagnostics[uri] = append(r.Diagnostics[uri], diag)
+			}
+		}
+	}
+}
+
+// printLSPReport writes the diagnostics from every check of the project to w
+// as a single JSON object.
+func printLSPReport(w io.Writer, report lspReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return errors.WithStack(enc.Encode(report))
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+func TestBuildLSPReportPathMismatch(t *testing.T) {
+	depSet, err := dependencies.NewProjectDependenciesFromModfileData(
+		nil,
+		"go.mod",
+		[]byte("// A comment.\nmodule example.com/wrong\n"),
+	)
+	if err != nil {
+		t.Fatalf("parsing modfile: %v", err)
+	}
+
+	report := modCheckCommand{}.buildLSPReport(checkFindings{
+		pathMismatches: []modulePathMismatch{
+			{depSet: depSet, expected: "example.com/proj"},
+		},
+	})
+
+	diags := report.Diagnostics[fileURI("go.mod")]
+	if len(diags) != 1 {
+		t.Fatalf("got diagnostics %+v, want 1 for go.mod", report.Diagnostics)
+	}
+
+	want := lspDiagnostic{
+		Range: lspRange{
+			Start: lspPosition{Line: 1, Character: 0},
+			End:   lspPosition{Line: 2, Character: 0},
+		},
+		Severity: lspSeverityError,
+		Message: "module example.com/wrong but its directory expects " +
+			"example.com/proj",
+		Source: lspSource,
+	}
+
+	if diags[0] != want {
+		t.Errorf("got diagnostic %+v, want %+v", diags[0], want)
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/alcionai/gomodcheck/pkg/manifest"
+)
+
+func (c modCheckCommand) verifyManifestFormat() error {
+	if len(c.manifestPath) == 0 {
+		return nil
+	}
+
+	if !slices.Contains(manifest.Formats(), c.manifestFormat) {
+		return errors.Errorf(
+			"unsupported manifest format %s, supported formats are: %s",
+			c.manifestFormat,
+			strings.Join(manifest.Formats(), ", "),
+		)
+	}
+
+	return nil
+}
+
+// findManifestDepErrors compares the module versions pinned in the external
+// manifest passed to the command against the effective versions in the
+// project. The manifest's version is the wanted version since the project is
+// expected to agree with the build system that consumes the manifest.
+func (c modCheckCommand) findManifestDepErrors() ([]depError, error) {
+	manifestDeps, err := manifest.Load(c.manifestFormat, c.manifestPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading manifest %s", c.manifestPath)
+	}
+
+	var res []depError
+
+	for _, manifestDep := range manifestDeps.AllDependencies() {
+		depPath := c.checker.CanonicalPath(manifestDep.OriginalVersion().Path)
+
+		for _, projectDepSet := range c.checker.ProjectDeps() {
+			projectDep := c.checker.GetProjectDep(projectDepSet, depPath)
+			if projectDep == nil {
+				continue
+			}
+
+			c.checker.MarkCompared(depPath)
+
+			wantVersion := c.checker.ComparableVersion(manifestDep)
+			gotVersion := c.checker.ComparableVersion(projectDep)
+
+			if wantVersion != gotVersion {
+				res = append(
+					res,
+					depError{
+						DepPath:     depPath,
+						GotPath:     projectDep.OriginalVersion().Path,
+						WantVersion: wantVersion,
+						GotVersion:  gotVersion,
+						GotLoc:      projectDep.Location(),
+						WantLoc:     manifestDep.Location(),
+						WantSource:  c.manifestFormat + " manifest " + c.manifestPath,
+					},
+				)
+			}
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// githubSummaryEnvVar is the environment variable GitHub Actions sets to the
+// path of the file job summary markdown should be appended to.
+const githubSummaryEnvVar = "GITHUB_STEP_SUMMARY"
+
+// escapeMarkdownCell makes the input safe to place in a markdown table cell.
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// writeMarkdownTable writes the dependency errors as a markdown table.
+func (c modCheckCommand) writeMarkdownTable(
+	w io.Writer,
+	depErrs []depError,
+) error {
+	var sb strings.Builder
+
+	sb.WriteString("### gomodcheck\n\n")
+
+	if len(depErrs) == 0 {
+		sb.WriteString("No dependency mismatches found.\n")
+
+		_, err := io.WriteString(w, sb.String())
+
+		return errors.WithStack(err)
+	}
+
+	sb.WriteString("| Dependency | Location | Have | Want | Want Source |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	for _, depErr := range depErrs {
+		var critical string
+
+		if c.isCritical(depErr.DepPath) {
+			critical = " (critical)"
+		}
+
+		fmt.Fprintf(
+			&sb,
+			"| `%s`%s | %s | `%s` | `%s` | %s |\n",
+			escapeMarkdownCell(depErr.DepPath),
+			critical,
+			escapeMarkdownCell(
+				c.effectiveLocationToString(depErr.GotLoc),
+			),
+			escapeMarkdownCell(depErr.GotVersion),
+			escapeMarkdownCell(depErr.WantVersion),
+			escapeMarkdownCell(depErr.WantSource),
+		)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return errors.WithStack(err)
+}
+
+// githubSummary returns the path of the file the GitHub job summary should be
+// written to or an empty string if no summary should be written.
+func (c modCheckCommand) githubSummary() string {
+	if len(c.githubSummaryPath) > 0 {
+		return c.githubSummaryPath
+	}
+
+	return os.Getenv(githubSummaryEnvVar)
+}
+
+// writeGithubSummary appends a markdown table of the dependency errors to the
+// GitHub Actions job summary file. It's a no-op if there's no summary file.
+func (c modCheckCommand) writeGithubSummary(depErrs []depError) error {
+	summaryPath := c.githubSummary()
+	if len(summaryPath) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(
+		summaryPath,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		0o644,
+	)
+	if err != nil {
+		return errors.Wrap(err, "opening summary file")
+	}
+
+	if err := c.writeMarkdownTable(f, depErrs); err != nil {
+		f.Close()
+		return errors.Wrap(err, "writing summary")
+	}
+
+	return errors.Wrap(f.Close(), "closing summary file")
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+)
+
+const metricsPrefix = "gomodcheck_"
+
+// runMetrics holds the counts from every check of the project so the metrics
+// file describes the whole run even if the project is checked once per
+// workspace.
+type runMetrics struct {
+	modulesScanned     int
+	modFilesLoaded     int
+	depsChecked        int
+	mismatches         int
+	criticalMismatches int
+}
+
+// record adds the counts from a check of the project. The sink is nil if the
+// check stopped before comparing dependencies.
+func (m *runMetrics) record(checker *engine.Checker, sink *depErrorSink) {
+	m.modulesScanned += len(checker.ProjectDeps())
+	m.modFilesLoaded += len(checker.LoadedDeps())
+	m.depsChecked += len(checker.ComparedDeps())
+
+	if sink != nil {
+		m.mismatches += sink.count
+		m.criticalMismatches += sink.criticalCount
+	}
+}
+
+// writeMetrics writes gauges describing the run to the metrics file in the
+// Prometheus text format. runErr is the error the run is returning. The file
+// is written even if nothing was found or the run failed so the time series
+// stays continuous.
+func (c modCheckCommand) writeMetrics(
+	runErr error,
+	duration time.Duration,
+) error {
+	var sb strings.Builder
+
+	writeGauge := func(name string, help string, value any) {
+		fmt.Fprintf(&sb, "# HELP %s%s %s\n", metricsPrefix, name, help)
+		fmt.Fprintf(&sb, "# TYPE %s%s gauge\n", metricsPrefix, name)
+		fmt.Fprintf(&sb, "%s%s %v\n", metricsPrefix, name, value)
+	}
+
+	writeGauge(
+		"modules_scanned",
+		"Number of project modules checked.",
+		c.metrics.modulesScanned,
+	)
+	writeGauge(
+		"modfiles_loaded",
+		"Number of modfiles loaded.",
+		c.metrics.modFilesLoaded,
+	)
+	writeGauge(
+		"deps_checked",
+		"Number of dependencies compared against a wanted version.",
+		c.metrics.depsChecked,
+	)
+
+	fmt.Fprintf(
+		&sb,
+		"# HELP %smismatches Number of dependency mismatches found.\n",
+		metricsPrefix,
+	)
+	fmt.Fprintf(&sb, "# TYPE %smismatches gauge\n", metricsPrefix)
+	fmt.Fprintf(
+		&sb,
+		"%smismatches{severity=\"critical\"} %d\n",
+		metricsPrefix,
+		c.metrics.criticalMismatches,
+	)
+	fmt.Fprintf(
+		&sb,
+		"%smismatches{severity=\"error\"} %d\n",
+		metricsPrefix,
+		c.metrics.mismatches-c.metrics.criticalMismatches,
+	)
+
+	writeGauge(
+		"exit_code",
+		"Exit code of the run. 1 if problems were found and 2 for other errors.",
+		ExitCode(runErr),
+	)
+	writeGauge(
+		"run_duration_seconds",
+		"Time taken to check the project.",
+		duration.Seconds(),
+	)
+
+	return errors.Wrap(
+		os.WriteFile(c.metricsPath, []byte(sb.String()), 0o644),
+		"writing metrics file",
+	)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestRunWritesMetrics(t *testing.T) {
+	table := []struct {
+		name      string
+		workFiles []string
+		wantLines []string
+	}{
+		{
+			name: "Findings",
+			wantLines: []string{
+				"gomodcheck_modules_scanned 1",
+				"gomodcheck_exit_code 1",
+			},
+		},
+		{
+			name:      "CheckFails",
+			workFiles: []string{"missing.work"},
+			wantLines: []string{
+				"gomodcheck_modules_scanned 0",
+				"gomodcheck_exit_code 2",
+			},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GOFLAGS", "")
+			t.Setenv("GOWORK", "off")
+			t.Setenv("GOPROXY", "off")
+
+			metricsPath := filepath.Join(t.TempDir(), "metrics.prom")
+
+			chdir(t, filepath.Join("testdata", "unused", "proj"))
+
+			// example.com/unused is never imported so the run has a finding.
+			c := &modCheckCommand{
+				failUnusedDirect: true,
+				failOn:           failOnAny,
+				outputFormat:     outputFormatText,
+				metricsPath:      metricsPath,
+				workFiles:        test.workFiles,
+			}
+
+			if err := c.newChecker(); err != nil {
+				t.Fatalf("creating checker: %v", err)
+			}
+
+			if err := c.run(context.Background(), []string{"./..."}); err == nil {
+				t.Fatal("expected an error")
+			}
+
+			metrics, err := os.ReadFile(metricsPath)
+			if err != nil {
+				t.Fatalf("reading metrics file: %v", err)
+			}
+
+			lines := strings.Split(string(metrics), "\n")
+
+			for _, want := range test.wantLines {
+				if !slices.Contains(lines, want) {
+					t.Errorf("metrics missing line %q:\n%s", want, metrics)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// modulePathMismatch describes a project module whose declared path doesn't
+// match its directory relative to the repo root.
+type modulePathMismatch struct {
+	depSet   dependencies.PackageDependencies
+	expected string
+}
+
+// moduleRootPrefix returns the module path expected for the repo root. If no
+// prefix was passed to the command the path of the module at the repo root is
+// used.
+func (c modCheckCommand) moduleRootPrefix() (string, error) {
+	if len(c.modulePathPrefix) > 0 {
+		return c.modulePathPrefix, nil
+	}
+
+	rootDeps, err := dependencies.NewProjectDependenciesFromModfile(
+		nil,
+		filepath.Join(c.repoRoot, "go.mod"),
+	)
+	if err != nil {
+		return "", errors.Wrapf(
+			err,
+			"getting module path of repo root, pass --%s to set it explicitly",
+			modulePathPrefixVarName,
+		)
+	}
+
+	return rootDeps.ModulePath(), nil
+}
+
+// matchesDir returns true if modulePath is the expected path for the module's
+// directory. Modules may add a major version suffix like /v2 to the expected
+// path.
+func matchesDir(modulePath string, expected string) bool {
+	if modulePath == expected {
+		return true
+	}
+
+	prefix, _, ok := module.SplitPathVersion(modulePath)
+
+	return ok && prefix == expected
+}
+
+// findModulePathMismatches compares the path of every project module with a
+// modfile against the path expected from the module's directory relative to
+// the repo root.
+func (c modCheckCommand) findModulePathMismatches() (
+	[]modulePathMismatch,
+	error,
+) {
+	prefix, err := c.moduleRootPrefix()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	root, err := filepath.Abs(c.repoRoot)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting absolute path of repo root")
+	}
+
+	var res []modulePathMismatch
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		modFilePath := projectDepSet.ModFilePath()
+		if len(modFilePath) == 0 {
+			continue
+		}
+
+		dir, err := filepath.Abs(filepath.Dir(modFilePath))
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting directory of %s", modFilePath)
+		}
+
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting relative path of %s", dir)
+		}
+
+		if rel == ".." ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			c.logVerbose(
+				"skipping module path check for %s: outside repo root %s",
+				modFilePath,
+				root,
+			)
+
+			continue
+		}
+
+		expected := path.Join(prefix, filepath.ToSlash(rel))
+
+		if !matchesDir(projectDepSet.ModulePath(), expected) {
+			res = append(
+				res,
+				modulePathMismatch{
+					depSet:   projectDepSet,
+					expected: expected,
+				},
+			)
+		}
+	}
+
+	return res, nil
+}
+
+func (c modCheckCommand) printModulePathMismatch(mismatch modulePathMismatch) {
+	loc := mismatch.depSet.ModuleLocation()
+
+	fmt.Fprintf(
+		os.Stderr,
+		"Module path mismatch: in modfile %s line %d, col %d: module %s but "+
+			"its directory expects %s\n",
+		c.formatPath(mismatch.depSet.ModFilePath()),
+		loc.Row,
+		loc.Col,
+		mismatch.depSet.ModulePath(),
+		mismatch.expected,
+	)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// stdinPath is the --packages-from value that reads patterns from stdin.
+const stdinPath = "-"
+
+// readPackagePatterns returns the package patterns listed in the file passed
+// to --packages-from, one per line. Blank lines and lines starting with # are
+// ignored.
+func (c modCheckCommand) readPackagePatterns() ([]string, error) {
+	var r io.Reader = os.Stdin
+
+	if c.packagesFromPath != stdinPath {
+		f, err := os.Open(c.packagesFromPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening package list")
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	var res []string
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		res = append(res, line)
+	}
+
+	return res, errors.Wrap(scanner.Err(), "reading package list")
+}
+
+// packagePatterns returns the package patterns to check, combining those
+// passed as arguments with those read from --packages-from.
+func (c modCheckCommand) packagePatterns(args []string) ([]string, error) {
+	patterns := append([]string(nil), args...)
+
+	if len(c.packagesFromPath) > 0 {
+		fromFile, err := c.readPackagePatterns()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		patterns = append(patterns, fromFile...)
+	}
+
+	if len(patterns) == 0 {
+		return nil, errors.New("no package patterns given")
+	}
+
+	return patterns, nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// lineageDepth returns the number of file locations in the ancestry chain of
+// loc, including loc itself.
+func lineageDepth(loc dependencies.LocationTree) int {
+	var res int
+
+	for ; loc != nil; loc = loc.Ancestor() {
+		res++
+	}
+
+	return res
+}
+
+// findDeepLineageDeps returns the dependencies from every loaded modfile whose
+// ancestry chain is longer than the max replace depth. Modfiles are visited in
+// path order so the output is stable between runs.
+func (c modCheckCommand) findDeepLineageDeps() []dependencies.Dependency {
+	var (
+		res      []dependencies.Dependency
+		modFiles = make([]string, 0, len(c.checker.LoadedDeps()))
+	)
+
+	for modFilePath := range c.checker.LoadedDeps() {
+		modFiles = append(modFiles, modFilePath)
+	}
+
+	sort.Strings(modFiles)
+
+	for _, modFilePath := range modFiles {
+		for _, dep := range c.checker.LoadedDeps()[modFilePath].AllDependencies() {
+			if lineageDepth(dep.Location()) > c.maxReplaceDepth {
+				res = append(res, dep)
+			}
+		}
+	}
+
+	return res
+}
+
+func (c modCheckCommand) printDeepLineageDep(dep dependencies.Dependency) {
+	fmt.Fprintf(
+		os.Stderr,
+		"Replace lineage too deep: in %s: %s has depth %d but max is %d\n%s",
+		c.effectiveLocationToString(dep.Location()),
+		dep.OriginalVersion().Path,
+		lineageDepth(dep.Location()),
+		c.maxReplaceDepth,
+		c.ancestryToString(dep.Location()),
+	)
+}
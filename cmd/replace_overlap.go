@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// replaceTargetOverlap describes a module that's required by a project modfile
+// and is also the target of a replace directive for a different module.
+type replaceTargetOverlap struct {
+	required dependencies.Dependency
+	replaced dependencies.Dependency
+}
+
+// findReplaceTargetOverlaps returns every project dependency whose path is
+// also used as the replacement of another module in the same modfile.
+func (c modCheckCommand) findReplaceTargetOverlaps() []replaceTargetOverlap {
+	var res []replaceTargetOverlap
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		for _, dep := range projectDepSet.AllDependencies() {
+			target := dep.EffectiveVersion().Path
+			if target == dep.OriginalVersion().Path {
+				continue
+			}
+
+			required := projectDepSet.GetDep(target)
+			if required == nil {
+				continue
+			}
+
+			res = append(
+				res,
+				replaceTargetOverlap{
+					required: required,
+					replaced: dep,
+				},
+			)
+		}
+	}
+
+	return res
+}
+
+func (c modCheckCommand) printReplaceTargetOverlap(
+	overlap replaceTargetOverlap,
+) {
+	requiredLoc := overlap.required.Location()
+	replacedLoc := overlap.replaced.Location()
+
+	fmt.Fprintf(
+		os.Stderr,
+		"Replace target overlap: in %s: %s is required and is also the "+
+			"replacement for %s\n\trequired:\n%s\treplaced:\n%s",
+		c.locationToString(requiredLoc, requiredLoc.OriginalLocation()),
+		overlap.required.OriginalVersion().Path,
+		overlap.replaced.OriginalVersion().Path,
+		c.ancestryToString(requiredLoc),
+		c.ancestryToString(replacedLoc),
+	)
+}
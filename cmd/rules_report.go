@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	rulesReportFormatJSON = "json"
+
+	ruleStatusMatched   = "matched"
+	ruleStatusUnmatched = "unmatched"
+
+	// latestFormatVersion is the newest schema version of the machine-readable
+	// output formats. Bump it and add it to supportedFormatVersions when making
+	// changes that could break consumers of the output.
+	latestFormatVersion = 1
+)
+
+// supportedFormatVersions contains the schema versions of the machine-readable
+// output formats that can be requested.
+var supportedFormatVersions = []int{1}
+
+// ruleReport describes whether a single match rule was used during a run.
+type ruleReport struct {
+	// Rule is the name of the flag the rule was specified with.
+	Rule string `json:"rule"`
+
+	// SourcePackage is the package path the wanted versions are sourced from.
+	SourcePackage string `json:"sourcePackage"`
+
+	// Dep is the target dependency for match-dep rules. It's empty for
+	// match-replaces rules since those check every replaced dependency.
+	Dep string `json:"dep,omitempty"`
+
+	// SourceLoaded denotes whether the source package was imported by the
+	// project and had its gomodfile loaded.
+	SourceLoaded bool `json:"sourceLoaded"`
+
+	// ComparedDeps contains the dep paths from this rule that were compared
+	// against a dep in the project.
+	ComparedDeps []string `json:"comparedDeps"`
+
+	// Status is matched if at least one comparison was made for this rule.
+	Status string `json:"status"`
+}
+
+type rulesReport struct {
+	// FormatVersion is the schema version of the report.
+	FormatVersion int `json:"formatVersion"`
+
+	Rules []ruleReport `json:"rules"`
+}
+
+func newRuleReport(
+	rule string,
+	sourcePackage string,
+	dep string,
+	sourceLoaded bool,
+	comparedDeps []string,
+) ruleReport {
+	status := ruleStatusUnmatched
+	if len(comparedDeps) > 0 {
+		status = ruleStatusMatched
+	}
+
+	sort.Strings(comparedDeps)
+
+	return ruleReport{
+		Rule:          rule,
+		SourcePackage: sourcePackage,
+		Dep:           dep,
+		SourceLoaded:  sourceLoaded,
+		ComparedDeps:  comparedDeps,
+		Status:        status,
+	}
+}
+
+// buildRulesReport creates the coverage report for every rule passed to the
+// command. It must be called after the checker compares the project so the set
+// of compared deps is populated.
+func (c modCheckCommand) buildRulesReport() rulesReport {
+	res := rulesReport{
+		FormatVersion: c.formatVersion,
+		Rules:         []ruleReport{},
+	}
+
+	for depPackage, matchDepSet := range c.checker.MatchDeps() {
+		depSet := c.checker.SourceDeps(depPackage)
+
+		for depPath := range matchDepSet {
+			compared := []string{}
+
+			if _, ok := c.checker.ComparedDeps()[c.checker.CanonicalPath(depPath)]; ok &&
+				depSet != nil && c.checker.GetDep(depSet, depPath) != nil {
+				compared = append(compared, depPath)
+			}
+
+			res.Rules = append(
+				res.Rules,
+				newRuleReport(
+					matchDepVarName,
+					depPackage,
+					depPath,
+					depSet != nil,
+					compared,
+				),
+			)
+		}
+	}
+
+	for _, depPackage := range c.checkReplacePackages {
+		depSet := c.checker.SourceDeps(depPackage)
+		compared := []string{}
+
+		if depSet != nil {
+			for _, dep := range depSet.Replacements() {
+				depPath := dep.OriginalVersion().Path
+
+				if _, ok := c.checker.ComparedDeps()[c.checker.CanonicalPath(depPath)]; ok {
+					compared = append(compared, depPath)
+				}
+			}
+		}
+
+		res.Rules = append(
+			res.Rules,
+			newRuleReport(
+				matchReplaceVarName,
+				depPackage,
+				"",
+				depSet != nil,
+				compared,
+			),
+		)
+	}
+
+	// Map iteration order is random so sort the output to keep it stable between
+	// runs.
+	sort.Slice(res.Rules, func(i, j int) bool {
+		a, b := res.Rules[i], res.Rules[j]
+
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+
+		if a.SourcePackage != b.SourcePackage {
+			return a.SourcePackage < b.SourcePackage
+		}
+
+		return a.Dep < b.Dep
+	})
+
+	return res
+}
+
+func (c modCheckCommand) printRulesReport(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return errors.WithStack(enc.Encode(c.buildRulesReport()))
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// depChange describes a dependency whose effective version differs between
+// the git tag passed to the command and the current modfile.
+type depChange struct {
+	module  string
+	depPath string
+
+	// dep is the current dependency. It's nil if the dependency was required at
+	// the tag but no longer is.
+	dep dependencies.Dependency
+
+	// tagVersion is the effective version at the tag. It's empty if the
+	// dependency wasn't required at the tag.
+	tagVersion string
+}
+
+// readModFileAtTag returns the contents of the modfile at modFilePath as of the
+// given git tag. It returns nil if the modfile didn't exist at the tag.
+func readModFileAtTag(
+	ctx context.Context,
+	tag string,
+	modFilePath string,
+) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	// The ./ prefix makes git resolve the path relative to the directory passed
+	// with -C instead of the root of the repo.
+	cmd := exec.CommandContext(
+		ctx,
+		"git",
+		"-C",
+		filepath.Dir(modFilePath),
+		"show",
+		tag+":./"+filepath.Base(modFilePath),
+	)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := stderr.String()
+
+		if strings.Contains(msg, "exists on disk, but not in") ||
+			strings.Contains(msg, "does not exist in") {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "git show: %s", strings.TrimSpace(msg))
+	}
+
+	return out, nil
+}
+
+// findChangesSinceTag compares the effective versions of the dependencies in
+// each project modfile against the same modfile at the tag passed to the
+// command. Both are read without workspace replaces so only changes to the
+// modfile are reported. Modules that didn't exist at the tag have all their
+// dependencies reported.
+func (c modCheckCommand) findChangesSinceTag(
+	ctx context.Context,
+) ([]depChange, error) {
+	var res []depChange
+
+	for _, projectDepSet := range c.checker.ProjectDeps() {
+		modFilePath := projectDepSet.ModFilePath()
+		if len(modFilePath) == 0 {
+			continue
+		}
+
+		current, err := dependencies.NewProjectDependenciesFromModfile(
+			nil,
+			modFilePath,
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading modfile %s", modFilePath)
+		}
+
+		data, err := readModFileAtTag(ctx, c.sinceTag, modFilePath)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"reading modfile %s at %s",
+				modFilePath,
+				c.sinceTag,
+			)
+		}
+
+		tagVersions := map[string]string{}
+
+		if data != nil {
+			atTag, err := dependencies.NewProjectDependenciesFromModfileData(
+				nil,
+				modFilePath,
+				data,
+			)
+			if err != nil {
+				return nil, errors.Wrapf(
+					err,
+					"loading modfile %s at %s",
+					modFilePath,
+					c.sinceTag,
+				)
+			}
+
+			for _, dep := range atTag.AllDependencies() {
+				tagVersions[dep.OriginalVersion().Path] = dep.EffectiveVersion().
+					String()
+			}
+		}
+
+		module := current.ModulePath()
+
+		for _, dep := range current.AllDependencies() {
+			depPath := dep.OriginalVersion().Path
+			tagVersion, ok := tagVersions[depPath]
+
+			if !ok || tagVersion != dep.EffectiveVersion().String() {
+				res = append(
+					res,
+					depChange{
+						module:     module,
+						depPath:    depPath,
+						dep:        dep,
+						tagVersion: tagVersion,
+					},
+				)
+			}
+		}
+
+		removed := make([]string, 0, len(tagVersions))
+
+		for depPath := range tagVersions {
+			if current.GetDep(depPath) == nil {
+				removed = append(removed, depPath)
+			}
+		}
+
+		sort.Strings(removed)
+
+		for _, depPath := range removed {
+			res = append(
+				res,
+				depChange{
+					module:     module,
+					depPath:    depPath,
+					tagVersion: tagVersions[depPath],
+				},
+			)
+		}
+	}
+
+	return res, nil
+}
+
+func (c modCheckCommand) printDepChange(change depChange) {
+	switch {
+	case change.dep == nil:
+		fmt.Fprintf(
+			os.Stderr,
+			"Changed since %s: module %s no longer requires %s (was %s)\n",
+			c.sinceTag,
+			change.module,
+			change.depPath,
+			change.tagVersion,
+		)
+
+	case len(change.tagVersion) == 0:
+		fmt.Fprintf(
+			os.Stderr,
+			"Changed since %s: in %s: added %s\n",
+			c.sinceTag,
+			c.effectiveLocationToString(change.dep.Location()),
+			change.dep.EffectiveVersion(),
+		)
+
+	default:
+		fmt.Fprintf(
+			os.Stderr,
+			"Changed since %s: in %s: %s -> %s\n",
+			c.sinceTag,
+			c.effectiveLocationToString(change.dep.Location()),
+			change.tagVersion,
+			change.dep.EffectiveVersion(),
+		)
+	}
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+)
+
+// suppression is an accepted mismatch between two specific versions of a dep.
+type suppression struct {
+	depPath     string
+	wantVersion string
+	gotVersion  string
+
+	// line is the line in the suppressions file the suppression was read from.
+	line int
+}
+
+// normalizeSuppressedVersion returns version with the directory of a local
+// path replacement joined onto baseDir and made absolute. This keeps
+// suppressions matching no matter how paths are rendered in the output.
+func normalizeSuppressedVersion(version, baseDir string) string {
+	dir, ok := strings.CutPrefix(version, engine.LocalReplaceLabel)
+	if !ok {
+		return version
+	}
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
+	}
+
+	return engine.LocalReplaceLabel + dir
+}
+
+// splitSuppression returns the fields of a line in the suppressions file.
+// Fields are separated by tabs if the line has any so that versions containing
+// spaces, like local path replacements, can be written. Otherwise they're
+// separated by any whitespace.
+func splitSuppression(line string) []string {
+	if !strings.Contains(line, "\t") {
+		return strings.Fields(line)
+	}
+
+	var res []string
+
+	for _, field := range strings.Split(line, "\t") {
+		if field = strings.TrimSpace(field); len(field) > 0 {
+			res = append(res, field)
+		}
+	}
+
+	return res
+}
+
+// readSuppressions parses the suppressions file passed to the command. Each
+// non-empty line that isn't a comment has the form
+// <dep path> <want version> <got version> where the versions are written as
+// they appear in mismatch output. Relative local path replacements are
+// resolved against the directory of the suppressions file.
+func (c *modCheckCommand) readSuppressions() error {
+	f, err := os.Open(c.suppressionsPath)
+	if err != nil {
+		return errors.Wrap(err, "opening suppressions file")
+	}
+	defer f.Close()
+
+	var (
+		lineNum int
+		baseDir = filepath.Dir(c.suppressionsPath)
+	)
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := splitSuppression(line)
+		if len(fields) != 3 {
+			return errors.Errorf(
+				"line %d: expected <dep path> <want version> <got version>",
+				lineNum,
+			)
+		}
+
+		c.suppressions = append(
+			c.suppressions,
+			suppression{
+				depPath:     c.checker.CanonicalPath(fields[0]),
+				wantVersion: normalizeSuppressedVersion(fields[1], baseDir),
+				gotVersion:  normalizeSuppressedVersion(fields[2], baseDir),
+				line:        lineNum,
+			},
+		)
+	}
+
+	return errors.Wrap(scanner.Err(), "reading suppressions file")
+}
+
+// suppress returns true if the dependency error exactly matches a suppression
+// and marks the matching suppressions in used. Errors for a suppressed dep
+// aren't suppressed if either version has changed.
+func (c modCheckCommand) suppress(depErr depError, used []bool) bool {
+	var suppressed bool
+
+	// Paths in the output are absolute or relative to the current directory.
+	wantVersion := normalizeSuppressedVersion(depErr.WantVersion, "")
+	gotVersion := normalizeSuppressedVersion(depErr.GotVersion, "")
+
+	for i, s := range c.suppressions {
+		if s.depPath == depErr.DepPath &&
+			s.wantVersion == wantVersion &&
+			s.gotVersion == gotVersion {
+			suppressed = true
+			used[i] = true
+		}
+	}
+
+	if suppressed {
+		c.logVerbose(
+			"suppressed mismatch for dep %s: have %s but want %s",
+			depErr.DepPath,
+			depErr.GotVersion,
+			depErr.WantVersion,
+		)
+	}
+
+	return suppressed
+}
+
+// unusedSuppressions returns the suppressions that aren't marked in used.
+func (c modCheckCommand) unusedSuppressions(used []bool) []suppression {
+	var res []suppression
+
+	for i, s := range c.suppressions {
+		if !used[i] {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+func (c modCheckCommand) printUnusedSuppression(s suppression) {
+	fmt.Fprintf(
+		os.Stderr,
+		"Unused suppression: in %s line %d: %s %s %s didn't match a mismatch\n",
+		c.formatPath(c.suppressionsPath),
+		s.line,
+		s.depPath,
+		s.wantVersion,
+		s.gotVersion,
+	)
+}
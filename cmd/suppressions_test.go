@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+)
+
+func TestSuppressLocalPathReplacement(t *testing.T) {
+	dir := t.TempDir()
+	otherDir := filepath.Join(dir, "other")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getting current directory: %v", err)
+	}
+
+	relOtherDir, err := filepath.Rel(wd, otherDir)
+	if err != nil {
+		t.Fatalf("getting relative path: %v", err)
+	}
+
+	table := []struct {
+		name string
+		line string
+
+		// gotVersion is the version of the mismatch as it's output.
+		gotVersion string
+	}{
+		{
+			name: "TabsRelativeToFile",
+			line: "example.com/other\texample.com/other@v1.2.0\t" +
+				"local path replacement other",
+			gotVersion: engine.LocalReplaceLabel + otherDir,
+		},
+		{
+			name: "TabsAbsolute",
+			line: "example.com/other\t\texample.com/other@v1.2.0\t" +
+				engine.LocalReplaceLabel + otherDir,
+			gotVersion: engine.LocalReplaceLabel + otherDir,
+		},
+		{
+			name: "RelativeOutputPaths",
+			line: "example.com/other\texample.com/other@v1.2.0\t" +
+				"local path replacement other",
+			gotVersion: engine.LocalReplaceLabel + relOtherDir,
+		},
+		{
+			name: "Spaces",
+			line: "example.com/foo example.com/foo@v1.2.0 example.com/foo@v1.1.0",
+		},
+	}
+
+	checker, err := engine.New(engine.Options{})
+	if err != nil {
+		t.Fatalf("creating checker: %v", err)
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, "suppressions.txt")
+
+			if err := os.WriteFile(path, []byte(test.line+"\n"), 0o600); err != nil {
+				t.Fatalf("writing suppressions file: %v", err)
+			}
+
+			c := modCheckCommand{checker: checker, suppressionsPath: path}
+
+			if err := c.readSuppressions(); err != nil {
+				t.Fatalf("reading suppressions: %v", err)
+			}
+
+			depErr := depError{
+				DepPath:     "example.com/other",
+				WantVersion: "example.com/other@v1.2.0",
+				GotVersion:  test.gotVersion,
+			}
+
+			if len(test.gotVersion) == 0 {
+				depErr = depError{
+					DepPath:     "example.com/foo",
+					WantVersion: "example.com/foo@v1.2.0",
+					GotVersion:  "example.com/foo@v1.1.0",
+				}
+			}
+
+			if !c.suppress(depErr, make([]bool, len(c.suppressions))) {
+				t.Errorf("mismatch %+v not suppressed by %q", depErr, test.line)
+			}
+		})
+	}
+}
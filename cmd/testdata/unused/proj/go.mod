@@ -0,0 +1,15 @@
+module example.com/proj
+
+go 1.21
+
+require (
+	example.com/testonly v1.0.0
+	example.com/tool v1.0.0
+	example.com/unused v1.0.0
+)
+
+replace (
+	example.com/testonly => ../testonly
+	example.com/tool => ../tool
+	example.com/unused => ../unused
+)
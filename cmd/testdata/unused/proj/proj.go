@@ -0,0 +1,3 @@
+package proj
+
+func F() {}
@@ -0,0 +1,11 @@
+package proj
+
+import (
+	"testing"
+
+	"example.com/testonly"
+)
+
+func TestF(t *testing.T) {
+	testonly.F()
+}
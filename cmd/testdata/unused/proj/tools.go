@@ -0,0 +1,7 @@
+//go:build tools
+
+package proj
+
+import (
+	_ "example.com/tool"
+)
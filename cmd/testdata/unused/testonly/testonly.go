@@ -0,0 +1,3 @@
+package testonly
+
+func F() {}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"sort"
+)
+
+// logUnhandledDirectives prints a verbose message for every modfile directive
+// that was present in a loaded modfile but not used when reading its
+// dependencies. This makes it obvious when the results may be incomplete.
+func (c modCheckCommand) logUnhandledDirectives() {
+	if !c.verbose {
+		return
+	}
+
+	modFiles := make([]string, 0, len(c.checker.LoadedDeps()))
+
+	for modFilePath := range c.checker.LoadedDeps() {
+		modFiles = append(modFiles, modFilePath)
+	}
+
+	sort.Strings(modFiles)
+
+	for _, modFilePath := range modFiles {
+		depSet := c.checker.LoadedDeps()[modFilePath]
+
+		for _, directive := range depSet.UnhandledDirectives() {
+			c.logVerbose(
+				"unhandled directive: %s at line %d in modfile %s",
+				directive.Kind,
+				directive.Location.Row,
+				c.formatPath(modFilePath),
+			)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// findUnusedDirectDeps returns the direct dependencies of project modfiles
+// that aren't imported by any of the loaded packages using that modfile. Only
+// modfiles that had packages loaded are checked since the imports of other
+// modfiles aren't known.
+//
+// Finding unused deps requires seeing every import. Test files and
+// tools.go-style files guarded by the tools build tag commonly import deps
+// that wouldn't otherwise be seen, so the packages are loaded again with them
+// included. A separate load keeps them from changing the other checks. It uses
+// the same options as the main checker so that paths are canonicalized the same
+// way.
+func (c modCheckCommand) findUnusedDirectDeps(
+	ctx context.Context,
+	packagePatterns []string,
+) ([]dependencies.Dependency, error) {
+	opts := c.checker.Options()
+	opts.Tests = true
+	opts.BuildFlags = append(slices.Clone(opts.BuildFlags), "-tags=tools")
+
+	checker, err := engine.New(opts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := checker.Load(ctx, packagePatterns...); err != nil {
+		return nil, errors.Wrap(err, "loading packages with tests and tools")
+	}
+
+	var res []dependencies.Dependency
+
+	for _, projectDepSet := range checker.ProjectDeps() {
+		imported, ok := checker.ImportedModules(projectDepSet)
+		if !ok {
+			continue
+		}
+
+		for _, dep := range projectDepSet.AllDependencies() {
+			if !dep.Direct() {
+				continue
+			}
+
+			if _, ok := imported[dep.OriginalVersion().Path]; !ok {
+				res = append(res, dep)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (c modCheckCommand) printUnusedDep(dep dependencies.Dependency) {
+	fmt.Fprintf(
+		os.Stderr,
+		"Unused direct dependency: in %s: %s is required but never imported\n",
+		c.locationToString(dep.Location(), dep.Location().OriginalLocation()),
+		dep.OriginalVersion().Path,
+	)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// chdir changes the current directory to dir until the test finishes.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getting current directory: %v", err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("changing directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Errorf("restoring directory: %v", err)
+		}
+	})
+}
+
+func TestFindUnusedDirectDeps(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOPROXY", "off")
+
+	chdir(t, filepath.Join("testdata", "unused", "proj"))
+
+	c := &modCheckCommand{failUnusedDirect: true}
+
+	if err := c.newChecker(); err != nil {
+		t.Fatalf("creating checker: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := c.checker.Load(ctx, "./..."); err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+
+	unused, err := c.findUnusedDirectDeps(ctx, []string{"./..."})
+	if err != nil {
+		t.Fatalf("finding unused deps: %v", err)
+	}
+
+	if len(unused) != 1 ||
+		unused[0].OriginalVersion().Path != "example.com/unused" {
+		var got []string
+
+		for _, dep := range unused {
+			got = append(got, dep.OriginalVersion().Path)
+		}
+
+		t.Errorf("got unused deps %v, want [example.com/unused]", got)
+	}
+
+	// Test and tools imports are only loaded for the unused dep check.
+	for _, depSet := range c.checker.ProjectDeps() {
+		imported, _ := c.checker.ImportedModules(depSet)
+
+		for _, depPath := range []string{"example.com/testonly", "example.com/tool"} {
+			if _, ok := imported[depPath]; ok {
+				t.Errorf("%s imported in the shared load", depPath)
+			}
+		}
+	}
+}
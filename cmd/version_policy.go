@@ -0,0 +1,442 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"golang.org/x/mod/semver"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// versionConstraint is a single comparison against a semantic version. An
+// empty op denotes a prefix match where the version must have the same major
+// (e.x. v1) or major and minor (e.x. v1.2) version as the constraint, or be
+// equal to it if the constraint has all three components.
+type versionConstraint struct {
+	op      string
+	version string
+}
+
+// constraintOps contains the supported comparison operators. Two character
+// operators must come first so they're matched before their prefixes.
+var constraintOps = []string{">=", "<=", ">", "<", "="}
+
+func parseVersionConstraint(input string) (versionConstraint, error) {
+	input = strings.TrimSpace(input)
+	res := versionConstraint{version: input}
+
+	for _, op := range constraintOps {
+		if strings.HasPrefix(input, op) {
+			res.op = op
+			res.version = strings.TrimSpace(strings.TrimPrefix(input, op))
+
+			break
+		}
+	}
+
+	if !semver.IsValid(res.version) {
+		return versionConstraint{}, errors.Errorf(
+			"invalid semantic version in constraint: %s",
+			input,
+		)
+	}
+
+	return res, nil
+}
+
+// parseVersionConstraints parses a comma separated list of constraints that
+// must all be satisfied.
+func parseVersionConstraints(input string) ([]versionConstraint, error) {
+	var res []versionConstraint
+
+	for _, part := range strings.Split(input, ",") {
+		constraint, err := parseVersionConstraint(part)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		res = append(res, constraint)
+	}
+
+	return res, nil
+}
+
+func (vc versionConstraint) satisfiedBy(version string) bool {
+	cmp := semver.Compare(version, vc.version)
+
+	switch vc.op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "=":
+		return cmp == 0
+	}
+
+	// No operator means a prefix match with the components given.
+	switch strings.Count(vc.version, ".") {
+	case 0:
+		return semver.Major(version) == vc.version
+	case 1:
+		return semver.MajorMinor(version) == vc.version
+	default:
+		return cmp == 0
+	}
+}
+
+func (vc versionConstraint) String() string {
+	return vc.op + vc.version
+}
+
+func constraintsSatisfiedBy(
+	constraints []versionConstraint,
+	version string,
+) bool {
+	for _, constraint := range constraints {
+		if !constraint.satisfiedBy(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func constraintsString(constraints []versionConstraint) string {
+	parts := make([]string, 0, len(constraints))
+
+	for _, constraint := range constraints {
+		parts = append(parts, constraint.String())
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// regexVersionRule requires every project dependency whose path matches
+// pathRegex to have a version satisfying all of the constraints, or starting
+// with a match of versionRegex if the rule has a version regex instead.
+type regexVersionRule struct {
+	raw          string
+	pathRegex    *regexp.Regexp
+	constraints  []versionConstraint
+	versionRegex *regexp.Regexp
+}
+
+func (r regexVersionRule) satisfiedBy(version string) bool {
+	if r.versionRegex != nil {
+		return r.versionRegex.MatchString(version)
+	}
+
+	return constraintsSatisfiedBy(r.constraints, version)
+}
+
+func (r regexVersionRule) want() string {
+	if r.versionRegex != nil {
+		return "version matching " + r.raw[strings.LastIndex(r.raw, "@")+1:]
+	}
+
+	return constraintsString(r.constraints)
+}
+
+// parseVersionPrefixRegex compiles input as a regex that must match the start
+// of a version, like v1\. for any v1 version.
+func parseVersionPrefixRegex(input string) (*regexp.Regexp, error) {
+	res, err := regexp.Compile("^(?:" + input + ")")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return res, nil
+}
+
+func (c *modCheckCommand) parseAndVerifyRegexVersionRules() error {
+	for _, input := range c.rawRegexVersionRules {
+		// Split on the last @ since module paths can't contain an @ but a regex
+		// could.
+		idx := strings.LastIndex(input, "@")
+		if idx <= 0 || idx == len(input)-1 {
+			return errors.Errorf("unexpected version regex input: %s", input)
+		}
+
+		pathRegex, err := regexp.Compile(input[:idx])
+		if err != nil {
+			return errors.Wrapf(err, "compiling path regex in %s", input)
+		}
+
+		rule := regexVersionRule{
+			raw:       input,
+			pathRegex: pathRegex,
+		}
+
+		// Anything that isn't a list of constraints is treated as a regex for
+		// the start of the version.
+		rule.constraints, err = parseVersionConstraints(input[idx+1:])
+		if err != nil {
+			var regexErr error
+
+			rule.versionRegex, regexErr = parseVersionPrefixRegex(input[idx+1:])
+			if regexErr != nil {
+				return errors.Wrapf(
+					err,
+					"parsing version constraints or regex in %s (regex: %v)",
+					input,
+					regexErr,
+				)
+			}
+		}
+
+		c.regexVersionRules = append(c.regexVersionRules, rule)
+	}
+
+	return nil
+}
+
+// versionViolation describes a project dependency whose version doesn't
+// satisfy a version rule.
+type versionViolation struct {
+	rule string
+	dep  dependencies.Dependency
+	want string
+}
+
+// findVersionRegexViolations checks every project dependency against the
+// regex version rules. Dependencies whose effective version isn't a semantic
+// version, like ones replaced with a local path, can't be checked and are
+// skipped.
+func (c modCheckCommand) findVersionRegexViolations() []versionViolation {
+	var res []versionViolation
+
+	for _, rule := range c.regexVersionRules {
+		for _, projectDepSet := range c.checker.ProjectDeps() {
+			for _, dep := range projectDepSet.AllDependencies() {
+				if !rule.pathRegex.MatchString(dep.OriginalVersion().Path) ||
+					!c.checker.IsReachable(dep.OriginalVersion().Path) {
+					continue
+				}
+
+				version := dep.EffectiveVersion().Version
+				if !semver.IsValid(version) {
+					c.logVerbose(
+						"skipping dep %s for rule %s: effective version %s isn't a "+
+							"semantic version",
+						dep.OriginalVersion().Path,
+						rule.raw,
+						dep.EffectiveVersion(),
+					)
+
+					continue
+				}
+
+				if !rule.satisfiedBy(version) {
+					res = append(
+						res,
+						versionViolation{
+							rule: rule.raw,
+							dep:  dep,
+							want: rule.want(),
+						},
+					)
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+func (c modCheckCommand) printVersionViolation(violation versionViolation) {
+	loc := violation.dep.Location()
+
+	fmt.Fprintf(
+		os.Stderr,
+		"Version policy violation: in %s: have version %s but want %s "+
+			"(rule %s)\n",
+		c.effectiveLocationToString(loc),
+		violation.dep.EffectiveVersion(),
+		violation.want,
+		violation.rule,
+	)
+}
+
+// requiredVersionRule requires the project dependency at path to have one of
+// the given versions.
+type requiredVersionRule struct {
+	raw      string
+	path     string
+	versions []string
+}
+
+func (c *modCheckCommand) parseAndVerifyRequiredVersionRules() error {
+	for _, input := range c.rawRequiredVersionRules {
+		idx := strings.LastIndex(input, "@")
+		if idx <= 0 || idx == len(input)-1 {
+			return errors.Errorf("unexpected required version input: %s", input)
+		}
+
+		rule := requiredVersionRule{
+			raw:  input,
+			path: input[:idx],
+		}
+
+		for _, version := range strings.Split(input[idx+1:], ",") {
+			version = strings.TrimSpace(version)
+
+			if !semver.IsValid(version) {
+				return errors.Errorf(
+					"invalid semantic version %s in %s",
+					version,
+					input,
+				)
+			}
+
+			rule.versions = append(rule.versions, version)
+		}
+
+		c.requiredVersionRules = append(c.requiredVersionRules, rule)
+	}
+
+	return nil
+}
+
+// findRequiredVersionViolations checks the project dependency for each
+// required version rule. A dependency that's replaced with a different module
+// or a local path doesn't satisfy the rule.
+func (c modCheckCommand) findRequiredVersionViolations() []versionViolation {
+	var res []versionViolation
+
+	for _, rule := range c.requiredVersionRules {
+		for _, projectDepSet := range c.checker.ProjectDeps() {
+			dep := c.checker.GetProjectDep(projectDepSet, rule.path)
+			if dep == nil {
+				continue
+			}
+
+			effective := dep.EffectiveVersion()
+
+			if c.checker.CanonicalPath(effective.Path) ==
+				c.checker.CanonicalPath(rule.path) &&
+				slices.Contains(rule.versions, effective.Version) {
+				continue
+			}
+
+			want := rule.versions[0]
+			if len(rule.versions) > 1 {
+				want = "one of " + strings.Join(rule.versions, ", ")
+			}
+
+			res = append(
+				res,
+				versionViolation{
+					rule: rule.raw,
+					dep:  dep,
+					want: want,
+				},
+			)
+		}
+	}
+
+	return res
+}
+
+// maxVersionRule forbids the project dependency at path from having a version
+// at or above version.
+type maxVersionRule struct {
+	raw     string
+	path    string
+	version string
+}
+
+func (c *modCheckCommand) parseAndVerifyMaxVersionRules() error {
+	for _, input := range c.rawMaxVersionRules {
+		idx := strings.LastIndex(input, "@")
+		if idx <= 0 || idx == len(input)-1 {
+			return errors.Errorf("unexpected max version input: %s", input)
+		}
+
+		version := strings.TrimSpace(input[idx+1:])
+		if !semver.IsValid(version) {
+			return errors.Errorf(
+				"invalid semantic version %s in %s",
+				version,
+				input,
+			)
+		}
+
+		c.maxVersionRules = append(
+			c.maxVersionRules,
+			maxVersionRule{
+				raw:     input,
+				path:    input[:idx],
+				version: version,
+			},
+		)
+	}
+
+	return nil
+}
+
+// findMaxVersionViolations checks the project dependency for each max version
+// rule. Dependencies replaced with a different module or whose effective
+// version isn't a semantic version can't be compared against the ceiling and
+// are skipped.
+func (c modCheckCommand) findMaxVersionViolations() []versionViolation {
+	var res []versionViolation
+
+	for _, rule := range c.maxVersionRules {
+		for _, projectDepSet := range c.checker.ProjectDeps() {
+			dep := c.checker.GetProjectDep(projectDepSet, rule.path)
+			if dep == nil {
+				continue
+			}
+
+			effective := dep.EffectiveVersion()
+
+			if c.checker.CanonicalPath(effective.Path) !=
+				c.checker.CanonicalPath(rule.path) {
+				c.logVerbose(
+					"skipping dep %s for rule %s: replaced with %s",
+					rule.path,
+					rule.raw,
+					effective,
+				)
+
+				continue
+			}
+
+			if !semver.IsValid(effective.Version) {
+				c.logVerbose(
+					"skipping dep %s for rule %s: effective version %s isn't a "+
+						"semantic version",
+					rule.path,
+					rule.raw,
+					effective,
+				)
+
+				continue
+			}
+
+			if semver.Compare(effective.Version, rule.version) < 0 {
+				continue
+			}
+
+			res = append(
+				res,
+				versionViolation{
+					rule: rule.raw,
+					dep:  dep,
+					want: "below " + rule.version,
+				},
+			)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestRegexVersionRuleSatisfiedBy(t *testing.T) {
+	table := []struct {
+		name    string
+		input   string
+		version string
+		want    bool
+	}{
+		{
+			name:    "ConstraintMatches",
+			input:   "^github.com/aws/.*@>=v1.2.0,<v2",
+			version: "v1.3.0",
+			want:    true,
+		},
+		{
+			name:    "ConstraintDoesNotMatch",
+			input:   "^github.com/aws/.*@>=v1.2.0,<v2",
+			version: "v2.0.0",
+		},
+		{
+			name:    "MajorPrefixConstraint",
+			input:   "^github.com/aws/.*@v1",
+			version: "v1.9.0",
+			want:    true,
+		},
+		{
+			name:    "VersionRegexMatches",
+			input:   `^github.com/aws/.*@v1\.`,
+			version: "v1.9.0",
+			want:    true,
+		},
+		{
+			name:    "VersionRegexDoesNotMatch",
+			input:   `^github.com/aws/.*@v1\.`,
+			version: "v10.0.0",
+		},
+		{
+			name:    "VersionRegexMatchesStartOnly",
+			input:   `^github.com/aws/.*@1\.`,
+			version: "v1.9.0",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			c := modCheckCommand{rawRegexVersionRules: []string{test.input}}
+
+			if err := c.parseAndVerifyRegexVersionRules(); err != nil {
+				t.Fatalf("parsing rule: %v", err)
+			}
+
+			got := c.regexVersionRules[0].satisfiedBy(test.version)
+			if got != test.want {
+				t.Errorf("got %t for version %s, want %t", got, test.version, test.want)
+			}
+		})
+	}
+}
+
+func TestRegexVersionRuleInvalid(t *testing.T) {
+	c := modCheckCommand{
+		rawRegexVersionRules: []string{"^github.com/aws/.*@v1("},
+	}
+
+	if err := c.parseAndVerifyRegexVersionRules(); err == nil {
+		t.Error("got no error for an invalid version regex")
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// checkWorkFiles checks the project once with each of the go.work files that
+// were passed to the command. Every workspace is checked even if an earlier
+// one had problems. The returned error is only a findings error if every
+// failed workspace failed because of problems found in the project.
+func (c *modCheckCommand) checkWorkFiles(
+	ctx context.Context,
+	packagePatterns []string,
+) error {
+	var (
+		failed      []string
+		operational bool
+	)
+
+	for _, workFile := range c.workFiles {
+		c.workFile = workFile
+
+		if err := c.newChecker(); err != nil {
+			return errors.WithStack(err)
+		}
+
+		fmt.Fprintf(os.Stderr, "Checking workspace %s\n", workFile)
+
+		if err := c.check(ctx, packagePatterns); err != nil {
+			fmt.Fprintf(os.Stderr, "workspace %s: %v\n", workFile, err)
+			failed = append(failed, workFile)
+			operational = operational || !isFindingsError(err)
+		}
+	}
+
+	if operational {
+		return errors.Errorf(
+			"checking workspaces failed: %s",
+			strings.Join(failed, ", "),
+		)
+	}
+
+	if len(failed) > 0 {
+		return findingsErrorf(
+			"checking workspaces failed: %s",
+			strings.Join(failed, ", "),
+		)
+	}
+
+	return nil
+}
+
+// workspaceEffectiveDeps returns the dependency that determines the version
+// the workspace builds with for every dep of a workspace module. Replaced deps
+// use the replacement. Otherwise the highest version required directly by the
+// workspace modules is used. This only approximates MVS since requirements of
+// other deps can raise the selected version further.
+func (c modCheckCommand) workspaceEffectiveDeps() map[string]dependencies.
+	Dependency {
+	res := map[string]dependencies.Dependency{}
+
+	for _, modFilePath := range c.checker.WorkspaceModFiles() {
+		depSet := c.checker.LoadedDeps()[modFilePath]
+		if depSet == nil {
+			continue
+		}
+
+		for _, dep := range depSet.AllDependencies() {
+			depPath := dep.OriginalVersion().Path
+			other, ok := res[depPath]
+			if !ok {
+				res[depPath] = dep
+				continue
+			}
+
+			_, replaced := dep.Location().ReplaceLocation()
+			_, otherReplaced := other.Location().ReplaceLocation()
+
+			switch {
+			case replaced:
+				res[depPath] = dep
+
+			case !otherReplaced &&
+				semver.Compare(
+					dep.OriginalVersion().Version,
+					other.OriginalVersion().Version,
+				) > 0:
+				res[depPath] = dep
+			}
+		}
+	}
+
+	return res
+}
+
+// findWorkspaceDivergences compares the effective version of every dep as
+// declared by each workspace module's own modfile against the version the
+// workspace builds with, including go.work replaces. The module's modfile is
+// re-read so replaces from the go.work file aren't applied to it.
+func (c modCheckCommand) findWorkspaceDivergences(
+	report func(depError),
+) error {
+	if len(c.checker.WorkspaceModFiles()) == 0 {
+		c.logVerbose("skipping workspace comparison: no active workspace")
+		return nil
+	}
+
+	effective := c.workspaceEffectiveDeps()
+
+	for _, modFilePath := range c.checker.WorkspaceModFiles() {
+		moduleDeps, err := dependencies.NewProjectDependenciesFromModfile(
+			nil,
+			modFilePath,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "loading workspace module %s", modFilePath)
+		}
+
+		for _, dep := range moduleDeps.AllDependencies() {
+			depPath := dep.OriginalVersion().Path
+
+			workspaceDep, ok := effective[depPath]
+			if !ok || !c.checker.IsReachable(depPath) {
+				continue
+			}
+
+			// Relative local replaces may be written differently in the modfile and
+			// the go.work file but still point at the same directory.
+			wantVersion := c.checker.ComparableVersion(workspaceDep)
+			gotVersion := c.checker.ComparableVersion(dep)
+
+			if wantVersion != gotVersion {
+				report(depError{
+					DepPath:     depPath,
+					GotPath:     depPath,
+					WantVersion: wantVersion,
+					GotVersion:  gotVersion,
+					GotLoc:      dep.Location(),
+					WantLoc:     workspaceDep.Location(),
+					WantSource:  "workspace " + c.formatPath(c.checker.WorkspaceFile()),
+				})
+			}
+		}
+	}
+
+	return nil
+}
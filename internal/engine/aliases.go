@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"golang.org/x/mod/module"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+func (c *Checker) parseAndVerifyAliases() error {
+	for _, input := range c.opts.Aliases {
+		parts := strings.Split(input, ":")
+
+		switch {
+		case len(parts) != 2:
+			return errors.Errorf("unexpected alias input: %s", input)
+
+		case len(parts[0]) == 0, len(parts[1]) == 0:
+			return errors.Errorf("empty package path in alias input: %s", input)
+
+		case parts[0] == parts[1]:
+			return errors.Errorf("package aliased to itself: %s", input)
+		}
+
+		alias, canonical := parts[0], parts[1]
+
+		if otherCanonical, ok := c.aliases[alias]; ok &&
+			otherCanonical != canonical {
+			return errors.Errorf(
+				"alias %s maps to multiple packages: %s and %s",
+				alias,
+				otherCanonical,
+				canonical,
+			)
+		}
+
+		c.aliases[alias] = canonical
+	}
+
+	// Don't allow chains of aliases as it makes it harder to reason about which
+	// path will be reported.
+	for alias, canonical := range c.aliases {
+		if _, ok := c.aliases[canonical]; ok {
+			return errors.Errorf(
+				"alias %s maps to package %s which is also an alias",
+				alias,
+				canonical,
+			)
+		}
+
+		c.aliasesOf[canonical] = append(c.aliasesOf[canonical], alias)
+	}
+
+	// Keep lookups deterministic if a modfile happens to contain more than one
+	// alias for the same package.
+	for _, aliases := range c.aliasesOf {
+		slices.Sort(aliases)
+	}
+
+	return nil
+}
+
+// CanonicalPath returns the path the given package path is an alias for. If
+// it isn't an alias the canonicalize rules are applied to it and the result is
+// checked against the aliases again.
+func (c Checker) CanonicalPath(packagePath string) string {
+	if canonical, ok := c.aliases[packagePath]; ok {
+		return canonical
+	}
+
+	rewritten := c.applyCanonicalizeRules(packagePath)
+
+	if canonical, ok := c.aliases[rewritten]; ok {
+		return canonical
+	}
+
+	return rewritten
+}
+
+// CanonicalVersion returns the string form of the module version using the
+// canonical path for the module so that aliased paths compare as equal.
+func (c Checker) CanonicalVersion(v module.Version) string {
+	v.Path = c.CanonicalPath(v.Path)
+	return v.String()
+}
+
+// LocalReplaceLabel prefixes the version of deps replaced with a local
+// directory. The rest of the version is the directory.
+const LocalReplaceLabel = "local path replacement "
+
+// ComparableVersion returns the string form of the dep's effective version to
+// use when comparing it against other deps. Deps replaced with a local
+// directory have no version so they're labeled and use the resolved directory
+// instead. This makes them only equal to replaces of the same directory, even
+// if the replaces were written relative to different modfiles.
+func (c Checker) ComparableVersion(dep dependencies.Dependency) string {
+	if dir := resolvedLocalPath(dep); len(dir) > 0 {
+		return LocalReplaceLabel + c.formatPath(dir)
+	}
+
+	return c.CanonicalVersion(dep.EffectiveVersion())
+}
+
+// ComparableVersionFrom is like ComparableVersion but resolves a relative
+// local directory against baseDir. It's used for deps that don't come from a
+// file, like those embedded in a binary.
+func (c Checker) ComparableVersionFrom(
+	dep dependencies.Dependency,
+	baseDir string,
+) string {
+	if !isLocalReplace(dep) {
+		return c.CanonicalVersion(dep.EffectiveVersion())
+	}
+
+	dir := dep.EffectiveVersion().Path
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+
+	return LocalReplaceLabel + c.formatPath(filepath.Clean(dir))
+}
+
+// GetDep returns the dependency for the given package path from depSet. If the
+// package isn't found using its canonical path each of its aliases is tried.
+// The returned dependency reports the path actually found in the modfile.
+func (c Checker) GetDep(
+	depSet dependencies.PackageDependencies,
+	packagePath string,
+) dependencies.Dependency {
+	canonical := c.CanonicalPath(packagePath)
+
+	if dep := depSet.GetDep(canonical); dep != nil {
+		return dep
+	}
+
+	for _, alias := range c.aliasesOf[canonical] {
+		if dep := depSet.GetDep(alias); dep != nil {
+			return dep
+		}
+	}
+
+	// Paths rewritten by canonicalize rules can't be looked up directly so
+	// fall back to checking the canonical path of every dep.
+	if len(c.canonicalizeRules) > 0 {
+		for _, dep := range depSet.AllDependencies() {
+			if c.CanonicalPath(dep.OriginalVersion().Path) == canonical {
+				return dep
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestComparableVersionFrom(t *testing.T) {
+	baseDir := filepath.Join(string(filepath.Separator), "src", "proj")
+
+	table := []struct {
+		name    string
+		replace string
+		want    string
+	}{
+		{
+			name:    "RelativeLocalReplace",
+			replace: "../x",
+			want: LocalReplaceLabel +
+				filepath.Join(string(filepath.Separator), "src", "x"),
+		},
+		{
+			name:    "AbsoluteLocalReplace",
+			replace: "/opt/x",
+			want:    LocalReplaceLabel + filepath.FromSlash("/opt/x"),
+		},
+		{
+			name:    "ModuleReplace",
+			replace: "example.com/y v1.1.0",
+			want:    "example.com/y@v1.1.0",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := New(Options{})
+			if err != nil {
+				t.Fatalf("creating checker: %v", err)
+			}
+
+			depSet := parseTestModFile(
+				t,
+				"other/go.mod",
+				"module example.com/proj\n\nrequire example.com/x v1.0.0\n\n"+
+					"replace example.com/x => "+test.replace+"\n",
+			)
+
+			got := c.ComparableVersionFrom(depSet.GetDep("example.com/x"), baseDir)
+			if got != test.want {
+				t.Errorf("got version %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// canonicalizeRule rewrites module paths matching pattern using replacement,
+// which may refer to capture groups in pattern.
+type canonicalizeRule struct {
+	raw         string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func (c *Checker) parseAndVerifyCanonicalizeRules() error {
+	for i, input := range c.opts.CanonicalizeRules {
+		// Split on the last = since module paths can't contain an = but a regex
+		// could.
+		idx := strings.LastIndex(input, "=")
+		if idx <= 0 || idx == len(input)-1 {
+			return errors.Errorf(
+				"canonicalize rule %d: unexpected input: %s",
+				i,
+				input,
+			)
+		}
+
+		pattern, err := regexp.Compile(input[:idx])
+		if err != nil {
+			return errors.Wrapf(err, "canonicalize rule %d: %s", i, input)
+		}
+
+		c.canonicalizeRules = append(
+			c.canonicalizeRules,
+			canonicalizeRule{
+				raw:         input,
+				pattern:     pattern,
+				replacement: input[idx+1:],
+			},
+		)
+	}
+
+	return nil
+}
+
+// applyCanonicalizeRules rewrites the given path with each canonicalize rule
+// in the order they were passed to the command.
+func (c Checker) applyCanonicalizeRules(path string) string {
+	for _, rule := range c.canonicalizeRules {
+		path = rule.pattern.ReplaceAllString(path, rule.replacement)
+	}
+
+	return path
+}
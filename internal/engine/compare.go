@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// Compare compares the wanted versions from every rule against the loaded
+// project's dependencies. Each mismatch is passed to report as soon as it's
+// found.
+func (c *Checker) Compare(report func(Mismatch)) error {
+	var (
+		// Maps from package path -> dependencies.Dependency that needs to be
+		// compared to the dependencies.Dependency in the main project.
+		depsToCheck = map[string]dependencies.Dependency{}
+
+		// Maps from package path -> description of the rule that added the entry
+		// in depsToCheck. Used to report conflicts between rules.
+		depSources = map[string]string{}
+	)
+
+	for depPackage, matchDepSet := range c.matchDeps {
+		depSet := c.depDeps[depPackage]
+		if depSet == nil {
+			// There either wasn't a gomodfile for this dep or the dep wasn't used by
+			// an import of the main project.
+			for depPath := range matchDepSet {
+				c.traceDep(
+					depPath,
+					"match-dep source %s not imported by project or has no modfile",
+					depPackage,
+				)
+			}
+
+			continue
+		}
+
+		for depPath := range matchDepSet {
+			dep := c.GetDep(depSet, depPath)
+			if dep == nil {
+				c.traceDep(depPath, "not required by match-dep source %s", depPackage)
+				continue
+			}
+
+			// Indirect deps may be stale since the package doesn't use them itself.
+			if c.opts.SourceDirectOnly && !dep.Direct() {
+				c.logf(
+					"skipping dep %s: indirect dependency of %s",
+					depPath,
+					depPackage,
+				)
+				c.traceDep(
+					depPath,
+					"skipped indirect requirement in match-dep source %s",
+					depPackage,
+				)
+
+				continue
+			}
+
+			c.traceDep(
+				depPath,
+				"want version sourced from match-dep source %s: %s",
+				depPackage,
+				c.describeDepVersion(dep),
+			)
+
+			depsToCheck[c.CanonicalPath(depPath)] = dep
+			depSources[c.CanonicalPath(depPath)] = "match-dep source " + depPackage
+		}
+	}
+
+	for _, depPackage := range c.opts.MatchReplaces {
+		depSet := c.depDeps[depPackage]
+		if depSet == nil {
+			continue
+		}
+
+		for _, dep := range depSet.Replacements() {
+			depPath := c.CanonicalPath(dep.OriginalVersion().Path)
+			source := "replace in " + depPackage
+
+			c.traceDep(
+				depPath,
+				"want version sourced from %s: %s",
+				source,
+				c.describeDepVersion(dep),
+			)
+
+			// We don't know upfront what replace directives deps will have so some
+			// other rule may have already asked for this dep to be checked. If both
+			// want the same version there's no problem, otherwise there's no correct
+			// version to pick.
+			if other, ok := depsToCheck[depPath]; ok {
+				otherVersion := c.ComparableVersion(other)
+				version := c.ComparableVersion(dep)
+
+				if otherVersion != version {
+					return errors.Errorf(
+						"conflicting wanted versions for dep %s: %s from %s and %s "+
+							"from %s",
+						depPath,
+						otherVersion,
+						depSources[depPath],
+						version,
+						source,
+					)
+				}
+
+				continue
+			}
+
+			depsToCheck[depPath] = dep
+			depSources[depPath] = source
+		}
+	}
+
+	if _, ok := depsToCheck[c.CanonicalPath(c.opts.TraceDep)]; !ok {
+		c.traceDep(c.opts.TraceDep, "no rule provided a want version")
+	}
+
+	for depPath, checkDep := range depsToCheck {
+		// A module can't meaningfully mismatch itself so don't compare the
+		// project's own modules even if a rule happens to name them.
+		if c.IsProjectModule(depPath) {
+			c.traceDep(depPath, "skipped since it's a project module")
+			continue
+		}
+
+		for _, projectDepSet := range c.projectDeps {
+			projectDep := c.GetProjectDep(projectDepSet, depPath)
+
+			// The project may require the same major version of the dep using the
+			// path from the other side of a +incompatible to module migration.
+			// Those always mismatch but deserve a clearer explanation.
+			var incompatibleMigration bool
+
+			if projectDep == nil {
+				projectDep = c.getIncompatibleCounterpart(projectDepSet, checkDep)
+				incompatibleMigration = projectDep != nil
+			}
+
+			if projectDep == nil {
+				continue
+			}
+
+			c.comparedDeps[depPath] = struct{}{}
+
+			wantVersion := c.ComparableVersion(checkDep)
+			gotVersion := c.ComparableVersion(projectDep)
+
+			c.traceDep(
+				depPath,
+				"got version from project: %s",
+				c.describeDepVersion(projectDep),
+			)
+			c.traceDep(
+				depPath,
+				"comparing want %s to got %s: equal=%t",
+				wantVersion,
+				gotVersion,
+				wantVersion == gotVersion,
+			)
+
+			if wantVersion != gotVersion && c.opts.SkipPrerelease &&
+				(isPrerelease(checkDep.EffectiveVersion()) ||
+					isPrerelease(projectDep.EffectiveVersion())) {
+				c.logf(
+					"skipping dep %s: want %s or got %s is a pre-release or "+
+						"pseudo-version",
+					depPath,
+					wantVersion,
+					gotVersion,
+				)
+				c.traceDep(depPath, "skipped mismatch with pre-release version")
+
+				continue
+			}
+
+			if wantVersion != gotVersion {
+				report(Mismatch{
+					DepPath:     depPath,
+					GotPath:     projectDep.OriginalVersion().Path,
+					WantVersion: wantVersion,
+					GotVersion:  gotVersion,
+					GotLoc:      projectDep.Location(),
+					WantLoc:     checkDep.Location(),
+					WantSource:  depSources[depPath],
+
+					IncompatibleMigration: incompatibleMigration,
+				})
+			}
+		}
+
+		if _, ok := c.comparedDeps[depPath]; !ok {
+			c.traceDep(depPath, "not required by any project modfile")
+		}
+	}
+
+	return nil
+}
+
+// isPrerelease returns true if the version is a pre-release or pseudo-version.
+func isPrerelease(v module.Version) bool {
+	return len(semver.Prerelease(v.Version)) > 0 ||
+		module.IsPseudoVersion(v.Version)
+}
+
+// GetProjectDep returns the dependency for the given package path from the
+// project dependency set if it's one that should be checked.
+func (c Checker) GetProjectDep(
+	projectDepSet dependencies.PackageDependencies,
+	packagePath string,
+) dependencies.Dependency {
+	dep := c.GetDep(projectDepSet, packagePath)
+	if dep == nil {
+		return nil
+	}
+
+	if !c.IsReachable(dep.OriginalVersion().Path) {
+		c.traceDep(packagePath, "skipped since no loaded package imports it")
+		return nil
+	}
+
+	return dep
+}
@@ -0,0 +1,306 @@
+// Package engine loads a project's dependencies and compares them against the
+// versions other modules want them to be at. It's shared by the gomodcheck
+// command and the check package. The command's extra checks need access to
+// everything that was loaded, so unlike the check package this API isn't
+// stable.
+package engine
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// Options configures a Checker. MatchDeps and MatchReplaces are the rules that
+// determine which dependencies are compared. The remaining fields are
+// optional.
+type Options struct {
+	// MatchDeps contains <package path>:<dep path> rules. The version of dep
+	// path required by the module providing package path must match the version
+	// the project uses.
+	MatchDeps []string
+
+	// MatchReplaces contains package paths whose module's replace directives
+	// the project must match.
+	MatchReplaces []string
+
+	// Aliases contains <alias path>:<canonical path> pairs of module paths that
+	// should be treated as the same dependency.
+	Aliases []string
+
+	// CanonicalizeRules contains <path regex>=<replacement> rules applied in
+	// order to module paths that aren't an alias.
+	CanonicalizeRules []string
+
+	// Dir is the directory to load packages from. If empty the current
+	// directory is used.
+	Dir string
+
+	// WorkFile is the go.work file to load the project with. Relative paths are
+	// resolved against Dir. If empty the file the go command detects is used.
+	WorkFile string
+
+	// ReachableOnly restricts comparisons to modules that provide a package in
+	// the transitive import graph of the loaded packages.
+	ReachableOnly bool
+
+	// SourceDirectOnly ignores wanted versions that come from indirect
+	// requirements of match-dep sources.
+	SourceDirectOnly bool
+
+	// SkipPrerelease ignores mismatches where either version is a pre-release
+	// or pseudo-version.
+	SkipPrerelease bool
+
+	// Tests and BuildFlags are passed through when loading packages.
+	Tests      bool
+	BuildFlags []string
+
+	// FormatPath returns the form of a file path to use in messages and in the
+	// versions of deps replaced with a local directory. Paths are used as is if
+	// it's nil.
+	FormatPath func(path string) string
+
+	// Log receives extra information about the run. It's discarded if nil.
+	Log io.Writer
+
+	// TraceDep is the path of a dep to describe each comparison step for.
+	// Steps are written to Trace.
+	TraceDep string
+	Trace    io.Writer
+}
+
+// Mismatch describes a project dependency whose effective version differs
+// from the version a rule wants it to be at.
+type Mismatch struct {
+	// DepPath is the canonical path of the dep that has mismatched versions.
+	DepPath string
+
+	// GotPath is the path the dep was found under in the project. It differs
+	// from DepPath if the project used an alias for the dep.
+	GotPath string
+
+	WantVersion string
+	GotVersion  string
+
+	GotLoc  dependencies.LocationTree
+	WantLoc dependencies.LocationTree
+
+	// WantSource describes where the wanted version came from, like the
+	// match-dep source package or the package whose replace was matched.
+	WantSource string
+
+	// IncompatibleMigration denotes that GotPath and DepPath are the
+	// +incompatible and module forms of the same major version of the dep.
+	IncompatibleMigration bool
+}
+
+// Checker loads a project's dependencies and compares them against the rules
+// it was created with. A Checker isn't safe for concurrent use.
+type Checker struct {
+	opts Options
+
+	// matchDeps is populated from opts.MatchDeps. It goes from
+	// <package path> -> set <dep path> where the package path is the path that
+	// will appear in the project's modfile and the dep path is the package path
+	// of the dependency the matching should be done on.
+	matchDeps map[string]map[string]struct{}
+
+	// aliases maps from alias path -> canonical path.
+	aliases map[string]string
+
+	// aliasesOf maps from canonical path -> all alias paths for it.
+	aliasesOf map[string][]string
+
+	canonicalizeRules []canonicalizeRule
+
+	loadedState
+}
+
+// loadedState is everything read while loading a project. It's reset at the
+// start of every load.
+type loadedState struct {
+	// projectDeps contains all dependency sets loaded from modfiles in the
+	// project.
+	projectDeps []dependencies.PackageDependencies
+
+	// depDeps contains package path -> dependency sets. The package path is used
+	// instead of the modfile path so that we can determine which dependencies
+	// to check.
+	depDeps map[string]dependencies.PackageDependencies
+
+	// allLoadedDeps maps from the path of every modfile read to the
+	// dependencies read from it.
+	allLoadedDeps map[string]dependencies.PackageDependencies
+
+	// comparedDeps contains the set of dep paths that were compared against a
+	// dep in the project.
+	comparedDeps map[string]struct{}
+
+	// importedModules maps from project dependency set -> the paths of modules
+	// imported by the loaded packages using it.
+	importedModules map[dependencies.PackageDependencies]map[string]struct{}
+
+	// reachableModules contains the paths of the modules reachable from the
+	// loaded packages. It's nil if checks aren't restricted to those modules.
+	reachableModules map[string]struct{}
+
+	// moduleModFiles maps from module path -> the modfile used for the module.
+	moduleModFiles map[string]string
+
+	// workspaceFile is the go.work file the project was loaded with or empty if
+	// there was no active workspace.
+	workspaceFile string
+
+	// workspaceModFiles contains the modfiles of the modules in the workspace.
+	workspaceModFiles []string
+}
+
+func newLoadedState() loadedState {
+	return loadedState{
+		depDeps:         map[string]dependencies.PackageDependencies{},
+		allLoadedDeps:   map[string]dependencies.PackageDependencies{},
+		comparedDeps:    map[string]struct{}{},
+		importedModules: map[dependencies.PackageDependencies]map[string]struct{}{},
+		moduleModFiles:  map[string]string{},
+	}
+}
+
+// New returns a Checker for the given options. It returns an error if any of
+// the rules are invalid, like a malformed match-dep rule or a dep whose wanted
+// version is sourced from multiple packages.
+func New(opts Options) (*Checker, error) {
+	res := &Checker{
+		opts:        opts,
+		matchDeps:   map[string]map[string]struct{}{},
+		aliases:     map[string]string{},
+		aliasesOf:   map[string][]string{},
+		loadedState: newLoadedState(),
+	}
+
+	// Aliases need to be parsed first so that match deps can be validated using
+	// canonical paths.
+	if err := res.parseAndVerifyAliases(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := res.parseAndVerifyCanonicalizeRules(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := res.parseAndVerifyMatchDeps(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return res, nil
+}
+
+// Options returns the options the checker was created with.
+func (c Checker) Options() Options {
+	return c.opts
+}
+
+// workFile returns the go.work file to load the project with, resolved
+// against Dir. It's empty if the go command should detect the file.
+func (c Checker) workFile() string {
+	if len(c.opts.Dir) > 0 && len(c.opts.WorkFile) > 0 &&
+		!filepath.IsAbs(c.opts.WorkFile) {
+		return filepath.Join(c.opts.Dir, c.opts.WorkFile)
+	}
+
+	return c.opts.WorkFile
+}
+
+// logf writes the formatted message to the log output if there is one.
+func (c Checker) logf(format string, args ...any) {
+	if c.opts.Log == nil {
+		return
+	}
+
+	fmt.Fprintf(c.opts.Log, format+"\n", args...)
+}
+
+// formatPath returns the path to output for the given file path.
+func (c Checker) formatPath(path string) string {
+	if c.opts.FormatPath == nil {
+		return path
+	}
+
+	return c.opts.FormatPath(path)
+}
+
+// ProjectDeps returns the dependency sets loaded from the project's modfiles.
+func (c Checker) ProjectDeps() []dependencies.PackageDependencies {
+	return c.projectDeps
+}
+
+// LoadedDeps returns every dependency set that was loaded, keyed by the path
+// of the modfile it was read from.
+func (c Checker) LoadedDeps() map[string]dependencies.PackageDependencies {
+	return c.allLoadedDeps
+}
+
+// MatchDeps returns the parsed match-dep rules. It maps from the package path
+// the wanted versions are sourced from to the set of dep paths to match.
+func (c Checker) MatchDeps() map[string]map[string]struct{} {
+	return c.matchDeps
+}
+
+// MatchReplaces returns the package paths whose replace directives are
+// matched.
+func (c Checker) MatchReplaces() []string {
+	return c.opts.MatchReplaces
+}
+
+// SourceDeps returns the dependency set of the rule source with the given
+// package path or nil if it wasn't loaded.
+func (c Checker) SourceDeps(
+	packagePath string,
+) dependencies.PackageDependencies {
+	return c.depDeps[packagePath]
+}
+
+// ComparedDeps returns the canonical paths of the deps that were compared
+// against a dep in the project.
+func (c Checker) ComparedDeps() map[string]struct{} {
+	return c.comparedDeps
+}
+
+// MarkCompared records that the dep was compared against a dep in the
+// project by a check outside of the Checker.
+func (c *Checker) MarkCompared(depPath string) {
+	c.comparedDeps[c.CanonicalPath(depPath)] = struct{}{}
+}
+
+// ImportedModules returns the paths of the modules imported by the loaded
+// packages that use the given project dependency set. It returns false if no
+// loaded package used the set.
+func (c Checker) ImportedModules(
+	depSet dependencies.PackageDependencies,
+) (map[string]struct{}, bool) {
+	res, ok := c.importedModules[depSet]
+	return res, ok
+}
+
+// ModuleModFile returns the path of the modfile used for the module with the
+// given path if a loaded package imported it.
+func (c Checker) ModuleModFile(modulePath string) (string, bool) {
+	res, ok := c.moduleModFiles[modulePath]
+	return res, ok
+}
+
+// WorkspaceFile returns the go.work file the project was loaded with or an
+// empty string if there was no active workspace.
+func (c Checker) WorkspaceFile() string {
+	return c.workspaceFile
+}
+
+// WorkspaceModFiles returns the modfiles of the modules in the active
+// workspace.
+func (c Checker) WorkspaceModFiles() []string {
+	return c.workspaceModFiles
+}
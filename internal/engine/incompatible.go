@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// IncompatibleSuffix is the suffix of versions of modules at a major version
+// above 1 that predate the module's go.mod file.
+const IncompatibleSuffix = "+incompatible"
+
+// incompatibleCounterpartPath returns the path the given module version would
+// have on the other side of a migration from +incompatible versions to a
+// module with a go.mod file at a major version above 1. For example,
+// example.com/foo@v2.0.0+incompatible and example.com/foo/v2 are counterparts.
+// Returns false if the version can't be part of such a migration.
+func incompatibleCounterpartPath(v module.Version) (string, bool) {
+	if strings.HasSuffix(v.Version, IncompatibleSuffix) {
+		return v.Path + "/" + semver.Major(v.Version), true
+	}
+
+	prefix, pathMajor, ok := module.SplitPathVersion(v.Path)
+
+	// gopkg.in paths use a .vN suffix and never have +incompatible versions.
+	if !ok || len(pathMajor) == 0 || !strings.HasPrefix(pathMajor, "/") {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+// getIncompatibleCounterpart returns the project dependency that's the other
+// side of a +incompatible to module migration from want. Both sides must be
+// for the same major version. Returns nil if there's no such dependency.
+func (c Checker) getIncompatibleCounterpart(
+	projectDepSet dependencies.PackageDependencies,
+	want dependencies.Dependency,
+) dependencies.Dependency {
+	counterpartPath, ok := incompatibleCounterpartPath(want.OriginalVersion())
+	if !ok {
+		return nil
+	}
+
+	dep := c.GetProjectDep(projectDepSet, counterpartPath)
+	if dep == nil {
+		return nil
+	}
+
+	if semver.Major(dep.OriginalVersion().Version) !=
+		semver.Major(want.OriginalVersion().Version) {
+		return nil
+	}
+
+	return dep
+}
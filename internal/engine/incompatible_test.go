@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"testing"
+
+	"golang.org/x/mod/module"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+func TestIncompatibleCounterpartPath(t *testing.T) {
+	table := []struct {
+		name     string
+		input    module.Version
+		wantPath string
+		wantOK   bool
+	}{
+		{
+			name: "Incompatible",
+			input: module.Version{
+				Path:    "example.com/foo",
+				Version: "v2.1.0+incompatible",
+			},
+			wantPath: "example.com/foo/v2",
+			wantOK:   true,
+		},
+		{
+			name:     "MajorVersionSuffix",
+			input:    module.Version{Path: "example.com/foo/v2", Version: "v2.1.0"},
+			wantPath: "example.com/foo",
+			wantOK:   true,
+		},
+		{
+			name:  "NoMajorVersionSuffix",
+			input: module.Version{Path: "example.com/foo", Version: "v1.2.0"},
+		},
+		{
+			name:  "GopkgIn",
+			input: module.Version{Path: "gopkg.in/yaml.v2", Version: "v2.4.0"},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			path, ok := incompatibleCounterpartPath(test.input)
+
+			if path != test.wantPath || ok != test.wantOK {
+				t.Errorf(
+					"got (%q, %t), want (%q, %t)",
+					path,
+					ok,
+					test.wantPath,
+					test.wantOK,
+				)
+			}
+		})
+	}
+}
+
+// parseTestModFile returns the dependencies in the given modfile contents.
+func parseTestModFile(
+	t *testing.T,
+	modFilePath string,
+	data string,
+) dependencies.PackageDependencies {
+	t.Helper()
+
+	res, err := dependencies.NewProjectDependenciesFromModfileData(
+		nil,
+		modFilePath,
+		[]byte(data),
+	)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", modFilePath, err)
+	}
+
+	return res
+}
+
+func TestCompareIncompatibleMigration(t *testing.T) {
+	table := []struct {
+		name string
+
+		// wantPath is the path of example.com/foo the rule source requires.
+		wantPath    string
+		wantVersion string
+
+		// gotPath is the path of example.com/foo the project requires.
+		gotPath    string
+		gotVersion string
+
+		// migration denotes whether a migration mismatch should be reported.
+		migration bool
+	}{
+		{
+			name:        "RuleWantsModuleProjectHasIncompatible",
+			wantPath:    "example.com/foo/v2",
+			wantVersion: "v2.1.0",
+			gotPath:     "example.com/foo",
+			gotVersion:  "v2.1.0+incompatible",
+			migration:   true,
+		},
+		{
+			name:        "RuleWantsIncompatibleProjectHasModule",
+			wantPath:    "example.com/foo",
+			wantVersion: "v2.1.0+incompatible",
+			gotPath:     "example.com/foo/v2",
+			gotVersion:  "v2.1.0",
+			migration:   true,
+		},
+		{
+			name:        "DifferentMajorVersions",
+			wantPath:    "example.com/foo",
+			wantVersion: "v2.1.0+incompatible",
+			gotPath:     "example.com/foo/v3",
+			gotVersion:  "v3.0.0",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := New(Options{
+				MatchDeps: []string{"example.com/dep:" + test.wantPath},
+			})
+			if err != nil {
+				t.Fatalf("creating checker: %v", err)
+			}
+
+			c.projectDeps = append(
+				c.projectDeps,
+				parseTestModFile(
+					t,
+					"proj/go.mod",
+					"module example.com/proj\n\nrequire "+test.gotPath+" "+
+						test.gotVersion+"\n",
+				),
+			)
+			c.depDeps["example.com/dep"] = parseTestModFile(
+				t,
+				"dep/go.mod",
+				"module example.com/dep\n\nrequire "+test.wantPath+" "+
+					test.wantVersion+"\n",
+			)
+
+			var mismatches []Mismatch
+
+			if err := c.Compare(func(m Mismatch) {
+				mismatches = append(mismatches, m)
+			}); err != nil {
+				t.Fatalf("comparing: %v", err)
+			}
+
+			if !test.migration {
+				if len(mismatches) != 0 {
+					t.Fatalf("got mismatches %+v, want none", mismatches)
+				}
+
+				return
+			}
+
+			if len(mismatches) != 1 {
+				t.Fatalf("got %d mismatches, want 1: %+v", len(mismatches), mismatches)
+			}
+
+			m := mismatches[0]
+
+			if !m.IncompatibleMigration {
+				t.Error("mismatch not marked as an incompatible migration")
+			}
+
+			if m.DepPath != test.wantPath || m.GotPath != test.gotPath {
+				t.Errorf(
+					"got dep %s found as %s, want %s found as %s",
+					m.DepPath,
+					m.GotPath,
+					test.wantPath,
+					test.gotPath,
+				)
+			}
+		})
+	}
+}
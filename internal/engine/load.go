@@ -0,0 +1,335 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+func (c *Checker) parseAndVerifyMatchDeps() error {
+	// Split the input into two parts, the package to check the dep version in and
+	// the dep name that we're checking the version of.
+	for _, input := range c.opts.MatchDeps {
+		parts := strings.Split(input, ":")
+
+		switch {
+		case len(parts) != 2:
+			return errors.Errorf("unexpected dep match input: %s", input)
+
+		case len(parts[0]) == 0, len(parts[1]) == 0:
+			return errors.Errorf(
+				"empty package path in dep match input: %s",
+				input,
+			)
+		}
+
+		if c.matchDeps[parts[0]] == nil {
+			c.matchDeps[parts[0]] = map[string]struct{}{}
+		}
+
+		c.matchDeps[parts[0]][parts[1]] = struct{}{}
+	}
+
+	// Make sure that each dep we're checking the version of only appears once.
+	validateTmp := make(map[string]string, len(c.matchDeps))
+
+	for packageName, depSet := range c.matchDeps {
+		for rawDep := range depSet {
+			dep := c.CanonicalPath(rawDep)
+
+			// We've already been asked to check the version of this dep by sourcing
+			// the version from a different package. Return an error.
+			if otherPackageName, ok := validateTmp[dep]; ok {
+				return errors.Errorf(
+					"dep %s being sourced from multiple packages: %s and %s",
+					dep,
+					otherPackageName,
+					packageName,
+				)
+			}
+
+			validateTmp[dep] = packageName
+		}
+	}
+
+	return nil
+}
+
+func (c *Checker) getOrLoadPackageDeps(
+	pkg *packages.Package,
+	dep dependencies.Dependency,
+) (dependencies.PackageDependencies, bool, error) {
+	if pkg.Module == nil {
+		return nil, false, nil
+	}
+
+	modFilePath := pkg.Module.GoMod
+
+	if pkg.Module.Replace != nil {
+		modFilePath = pkg.Module.Replace.GoMod
+	}
+
+	return c.getOrLoadModFileDeps(modFilePath, dep)
+}
+
+func (c *Checker) getOrLoadModFileDeps(
+	modFilePath string,
+	dep dependencies.Dependency,
+) (dependencies.PackageDependencies, bool, error) {
+	// No gomodfile specified, check the next package.
+	if len(modFilePath) == 0 {
+		return nil, false, nil
+	}
+
+	// We've already loaded info for this particular gomodfile. No need to load
+	// it again so continue on.
+	if deps := c.allLoadedDeps[modFilePath]; deps != nil {
+		return deps, false, nil
+	}
+
+	// We actually need to go load data.
+	deps, err := dependencies.NewProjectDependenciesFromModfile(dep, modFilePath)
+	if err != nil {
+		return nil, false, errors.Wrapf(
+			err,
+			"loading dependency info for: %s",
+			modFilePath,
+		)
+	}
+
+	c.allLoadedDeps[modFilePath] = deps
+
+	return deps, true, nil
+}
+
+func checkPackagesLoaded(pkgs []*packages.Package) error {
+	var pkgErr error
+
+	for _, pkg := range pkgs {
+		if pkg.Module != nil {
+			return nil
+		}
+
+		if pkgErr == nil && len(pkg.Errors) > 0 {
+			pkgErr = pkg.Errors[0]
+		}
+	}
+
+	if pkgErr != nil {
+		return errors.Wrap(pkgErr, "no packages in a module loaded")
+	}
+
+	return errors.New("no packages in a module loaded")
+}
+
+// Load reads the modfiles of the project packages matching the patterns, the
+// modfiles of the rule sources they import, and the modfiles of any modules in
+// the active workspace. Anything loaded by a previous call is discarded.
+func (c *Checker) Load(ctx context.Context, packagePatterns ...string) error {
+	c.loadedState = newLoadedState()
+
+	cfg := &packages.Config{
+		Context:    ctx,
+		Dir:        c.opts.Dir,
+		Mode:       packages.NeedName | packages.NeedImports | packages.NeedModule,
+		Tests:      c.opts.Tests,
+		BuildFlags: c.opts.BuildFlags,
+	}
+
+	// The go command requires GOWORK to be an absolute path.
+	if len(c.workFile()) > 0 {
+		workFile, err := filepath.Abs(c.workFile())
+		if err != nil {
+			return errors.Wrap(err, "getting absolute path of go.work file")
+		}
+
+		cfg.Env = append(os.Environ(), "GOWORK="+workFile)
+	}
+
+	if c.opts.ReachableOnly {
+		cfg.Mode |= packages.NeedDeps
+	}
+
+	pkgs, err := packages.Load(cfg, packagePatterns...)
+	if err != nil {
+		return errors.Wrap(c.explainLoadError(err), "getting packages")
+	}
+
+	// Continuing would result in nothing being checked which would look the same
+	// as a successful run. Bad patterns don't always result in an empty set of
+	// packages, go list may instead return a placeholder package with an error
+	// and no module info.
+	if err := checkPackagesLoaded(pkgs); err != nil {
+		return errors.Wrapf(
+			c.explainLoadError(err),
+			"patterns %s",
+			strings.Join(packagePatterns, " "),
+		)
+	}
+
+	if c.opts.ReachableOnly {
+		c.recordReachableModules(pkgs)
+	}
+
+	// Every project modfile, including those of workspace modules, needs to be
+	// known before imports are walked. Otherwise a project module imported by a
+	// package loaded before the module's own packages would be treated as a rule
+	// source.
+	pkgDepSets := make([]dependencies.PackageDependencies, len(pkgs))
+
+	for i, pkg := range pkgs {
+		pkgDepSet, freshLoad, err := c.getOrLoadPackageDeps(pkg, nil)
+		if err != nil {
+			return errors.Wrap(err, "loading project deps")
+		} else if freshLoad {
+			c.projectDeps = append(c.projectDeps, pkgDepSet)
+		}
+
+		pkgDepSets[i] = pkgDepSet
+	}
+
+	// Workspace modules are part of the project even if none of their packages
+	// were loaded.
+	if err := c.readWorkspaceDeps(ctx); err != nil {
+		return errors.Wrap(err, "loading workspace deps")
+	}
+
+	for i, pkg := range pkgs {
+		pkgDepSet := pkgDepSets[i]
+
+		// Go through the imports in this package. If any of them are in the list of
+		// packages that we're going to compare against load them as well.
+		for _, importPkg := range pkg.Imports {
+			var importPkgPath string
+
+			if importPkg.Module != nil {
+				importPkgPath = importPkg.Module.Path
+			}
+
+			if pkgDepSet != nil {
+				c.recordImportedModule(pkgDepSet, importPkgPath)
+			}
+
+			c.recordModuleModFile(importPkg)
+
+			// If the package backing this import isn't one of the ones we're going to
+			// check against then don't bother loading it.
+			if _, ok := c.matchDeps[importPkgPath]; !ok &&
+				!slices.Contains(c.opts.MatchReplaces, importPkgPath) {
+				continue
+			}
+
+			// Packages in the project's own module share its modfile so there's
+			// nothing to compare them against.
+			if c.IsProjectModule(importPkgPath) {
+				continue
+			}
+
+			// Pull the dep info on this package that we've already parsed from the
+			// current gomodfile. This allows us to build a full lineage of file
+			// locations.
+			importDep := pkgDepSet.GetDep(importPkgPath)
+
+			if deps, freshLoad, err := c.getOrLoadPackageDeps(
+				importPkg,
+				importDep,
+			); err != nil {
+				return errors.Wrapf(
+					err,
+					"loading deps for dependency %s",
+					importPkgPath,
+				)
+			} else if freshLoad {
+				c.depDeps[importPkgPath] = deps
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Checker) recordImportedModule(
+	depSet dependencies.PackageDependencies,
+	modulePath string,
+) {
+	if len(modulePath) == 0 {
+		return
+	}
+
+	if c.importedModules[depSet] == nil {
+		c.importedModules[depSet] = map[string]struct{}{}
+	}
+
+	c.importedModules[depSet][modulePath] = struct{}{}
+}
+
+// recordModuleModFile saves the path of the modfile used for the module
+// providing pkg so it can be loaded later if needed.
+func (c *Checker) recordModuleModFile(pkg *packages.Package) {
+	if pkg.Module == nil {
+		return
+	}
+
+	modFilePath := pkg.Module.GoMod
+
+	if pkg.Module.Replace != nil {
+		modFilePath = pkg.Module.Replace.GoMod
+	}
+
+	if len(modFilePath) > 0 {
+		c.moduleModFiles[pkg.Module.Path] = modFilePath
+	}
+}
+
+// recordReachableModules records the path of every module that provides a
+// package in the transitive import graph of pkgs. The packages must have been
+// loaded with packages.NeedDeps.
+func (c *Checker) recordReachableModules(pkgs []*packages.Package) {
+	c.reachableModules = map[string]struct{}{}
+
+	packages.Visit(
+		pkgs,
+		func(pkg *packages.Package) bool {
+			if pkg.Module != nil {
+				c.reachableModules[pkg.Module.Path] = struct{}{}
+			}
+
+			return true
+		},
+		nil,
+	)
+}
+
+// IsReachable returns true if the module at path provides a package reachable
+// from the loaded packages. All modules are reachable if checking wasn't
+// scoped to reachable modules.
+func (c Checker) IsReachable(path string) bool {
+	if c.reachableModules == nil {
+		return true
+	}
+
+	_, ok := c.reachableModules[path]
+
+	return ok
+}
+
+// IsProjectModule returns true if the given path, or the path it's an alias
+// of, is the module path of one of the project's modfiles.
+func (c Checker) IsProjectModule(path string) bool {
+	canonical := c.CanonicalPath(path)
+
+	for _, projectDepSet := range c.projectDeps {
+		if c.CanonicalPath(projectDepSet.ModulePath()) == canonical {
+			return true
+		}
+	}
+
+	return false
+}
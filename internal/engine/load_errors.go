@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// loadErrorHint maps a substring of an opaque error from loading packages to
+// a message telling the user how to fix it.
+type loadErrorHint struct {
+	matches []string
+	message string
+}
+
+// loadErrorHints are checked in order and the first one with a matching
+// substring is used.
+var loadErrorHints = []loadErrorHint{
+	{
+		matches: []string{"missing go.sum entry"},
+		message: "go.sum is missing entries for some modules; run " +
+			"`go mod download` or `go mod tidy` and try again",
+	},
+	{
+		matches: []string{
+			"updates to go.mod needed",
+			"disabled by -mod=readonly",
+			"disabled by -mod=vendor",
+			"inconsistent vendoring",
+		},
+		message: "go.mod needs updates that the go command isn't allowed to " +
+			"make; run `go mod tidy` or set GOFLAGS=-mod=mod and try again",
+	},
+	{
+		matches: []string{"parsing $GOFLAGS", "flag provided but not defined"},
+		message: "the go command rejected the flags in GOFLAGS; check " +
+			"`go env GOFLAGS` and try again",
+	},
+	{
+		matches: []string{"go.mod file not found", "cannot find main module"},
+		message: "no go.mod file found; run from inside a module or use a " +
+			"go.work file",
+	},
+}
+
+// explainLoadError replaces common errors from loading packages with an
+// actionable message. The original error is only written to the log output
+// since it's usually just the go command's output. Errors without a known
+// cause are returned unchanged.
+func (c Checker) explainLoadError(err error) error {
+	msg := err.Error()
+
+	for _, hint := range loadErrorHints {
+		for _, match := range hint.matches {
+			if !strings.Contains(msg, match) {
+				continue
+			}
+
+			c.logf("error loading packages: %v", err)
+
+			return errors.New(hint.message)
+		}
+	}
+
+	return err
+}
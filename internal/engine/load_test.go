@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// isolateGoEnv keeps settings from the environment running the tests from
+// changing how the go command loads the fixture modules.
+func isolateGoEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOPROXY", "off")
+}
+
+// loadAndCompare loads the patterns with a checker for opts and returns the
+// checker along with every mismatch it found.
+func loadAndCompare(
+	t *testing.T,
+	opts Options,
+	packagePatterns ...string,
+) (*Checker, []Mismatch) {
+	t.Helper()
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("creating checker: %v", err)
+	}
+
+	if err := c.Load(context.Background(), packagePatterns...); err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+
+	var res []Mismatch
+
+	if err := c.Compare(func(m Mismatch) { res = append(res, m) }); err != nil {
+		t.Fatalf("comparing: %v", err)
+	}
+
+	return c, res
+}
+
+// projectModulePaths returns the sorted module paths of the checker's project
+// dependency sets.
+func projectModulePaths(c *Checker) []string {
+	var res []string
+
+	for _, depSet := range c.ProjectDeps() {
+		res = append(res, depSet.ModulePath())
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+func TestLoadMainModuleMatchesRule(t *testing.T) {
+	isolateGoEnv(t)
+
+	c, mismatches := loadAndCompare(
+		t,
+		Options{
+			Dir:           filepath.Join("testdata", "mainmodule", "proj"),
+			MatchDeps:     []string{"example.com/dep:example.com/proj"},
+			MatchReplaces: []string{"example.com/proj"},
+		},
+		"./...",
+	)
+
+	if len(mismatches) != 0 {
+		t.Errorf("got mismatches %+v, want none", mismatches)
+	}
+
+	got := projectModulePaths(c)
+	if len(got) != 1 || got[0] != "example.com/proj" {
+		t.Errorf("got project modules %v, want [example.com/proj]", got)
+	}
+
+	if c.SourceDeps("example.com/proj") != nil {
+		t.Error("main module loaded as a rule source")
+	}
+
+	if c.SourceDeps("example.com/dep") == nil {
+		t.Error("match-dep source example.com/dep not loaded")
+	}
+
+	if _, ok := c.ComparedDeps()["example.com/proj"]; ok {
+		t.Error("main module compared against itself")
+	}
+}
+
+func TestLoadWorkspaceModuleMatchesRule(t *testing.T) {
+	table := []struct {
+		name     string
+		patterns []string
+	}{
+		{
+			// The imported package from example.com/b isn't matched by the patterns
+			// but another package in its module is, after the importing package.
+			name:     "ImportedModuleOtherPackage",
+			patterns: []string{"./a/...", "./b/tool"},
+		},
+		{
+			name:     "AllModules",
+			patterns: []string{"./a/...", "./b/..."},
+		},
+		{
+			name:     "ImportingModuleOnly",
+			patterns: []string{"./a/..."},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			isolateGoEnv(t)
+
+			c, mismatches := loadAndCompare(
+				t,
+				Options{
+					Dir:           filepath.Join("testdata", "workspace"),
+					WorkFile:      "go.work",
+					MatchReplaces: []string{"example.com/b"},
+				},
+				test.patterns...,
+			)
+
+			if len(mismatches) != 0 {
+				t.Errorf("got mismatches %+v, want none", mismatches)
+			}
+
+			got := projectModulePaths(c)
+			if len(got) != 2 ||
+				got[0] != "example.com/a" ||
+				got[1] != "example.com/b" {
+				t.Errorf(
+					"got project modules %v, want [example.com/a example.com/b]",
+					got,
+				)
+			}
+
+			if c.SourceDeps("example.com/b") != nil {
+				t.Error("workspace module loaded as a rule source")
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+module example.com/dep
+
+go 1.21
+
+require example.com/proj v1.0.0
+
+replace example.com/proj => example.com/proj v1.1.0
@@ -0,0 +1,6 @@
+package a
+
+import (
+	_ "example.com/dep"
+	_ "example.com/proj/b"
+)
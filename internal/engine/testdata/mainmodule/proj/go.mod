@@ -0,0 +1,7 @@
+module example.com/proj
+
+go 1.21
+
+require example.com/dep v1.0.0
+
+replace example.com/dep => ../dep
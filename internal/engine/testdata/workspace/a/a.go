@@ -0,0 +1,6 @@
+package a
+
+import (
+	_ "example.com/b"
+	_ "example.com/x"
+)
@@ -0,0 +1,5 @@
+module example.com/a
+
+go 1.21
+
+require example.com/x v1.0.0
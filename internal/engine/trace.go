@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// traceDep writes the formatted message to the trace output if depPath is the
+// dep that tracing was requested for.
+func (c Checker) traceDep(depPath string, format string, args ...any) {
+	if c.opts.Trace == nil || len(c.opts.TraceDep) == 0 ||
+		c.CanonicalPath(depPath) != c.CanonicalPath(c.opts.TraceDep) {
+		return
+	}
+
+	fmt.Fprintf(
+		c.opts.Trace,
+		"trace %s: "+format+"\n",
+		append([]any{c.opts.TraceDep}, args...)...,
+	)
+}
+
+// describeDepVersion returns a description of the dep's versions and where
+// they were set for use in trace output.
+func (c Checker) describeDepVersion(dep dependencies.Dependency) string {
+	loc := dep.Location()
+
+	res := fmt.Sprintf(
+		"original version %s in %s",
+		dep.OriginalVersion(),
+		dependencies.LocationString(loc, loc.OriginalLocation(), c.formatPath),
+	)
+
+	if rep, ok := loc.ReplaceLocation(); ok {
+		res += fmt.Sprintf(
+			", replaced with %s at line %d, col %d",
+			dep.EffectiveVersion(),
+			rep.Row,
+			rep.Col,
+		)
+	} else {
+		res += ", not replaced"
+	}
+
+	return res
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"golang.org/x/mod/modfile"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// activeWorkFile returns the path of the go.work file to load the project
+// with. If no go.work file was set in the options it returns the file the go
+// command will use in the current directory or an empty string if workspace
+// mode is disabled.
+func (c Checker) activeWorkFile(ctx context.Context) (string, error) {
+	if workFile := c.workFile(); len(workFile) > 0 {
+		return workFile, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "env", "GOWORK")
+	cmd.Dir = c.opts.Dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrap(err, "getting active go.work file")
+	}
+
+	workFile := strings.TrimSpace(string(out))
+
+	// `go env` reports "off" if the user explicitly disabled workspaces.
+	if workFile == "off" {
+		return "", nil
+	}
+
+	return workFile, nil
+}
+
+// readWorkspaceDeps adds the gomodfile of every module referenced by a use
+// directive in the active go.work file to the set of project deps, even if
+// the loaded packages don't import the module. The workspace's replace
+// directives are then applied to all project deps. It's a no-op if there's no
+// active workspace.
+func (c *Checker) readWorkspaceDeps(ctx context.Context) error {
+	workFile, err := c.activeWorkFile(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(workFile) == 0 {
+		return nil
+	}
+
+	workspace, err := dependencies.ReadWorkspace(workFile)
+	if err != nil {
+		return errors.Wrapf(err, "reading workspace %s", workFile)
+	}
+
+	c.workspaceFile = workFile
+	c.workspaceModFiles = workspace.ModFiles()
+
+	for _, modFilePath := range workspace.ModFiles() {
+		deps, _, err := c.getOrLoadModFileDeps(modFilePath, nil)
+		if err != nil {
+			return errors.Wrap(err, "loading workspace module deps")
+		}
+
+		// The module may have already been loaded either because it was part of
+		// the package pattern or because it was imported as a dep to check.
+		if deps != nil && !slices.Contains(c.projectDeps, deps) {
+			c.projectDeps = append(c.projectDeps, deps)
+		}
+	}
+
+	for _, deps := range c.projectDeps {
+		if err := workspace.ApplyReplaces(deps); err != nil {
+			return errors.Wrapf(err, "applying workspace %s replaces", workFile)
+		}
+	}
+
+	return nil
+}
+
+// isLocalReplace returns true if dep is replaced with a local directory.
+func isLocalReplace(dep dependencies.Dependency) bool {
+	return modfile.IsDirectoryPath(dep.EffectiveVersion().Path)
+}
+
+// resolvedLocalPath returns the absolute directory dep is replaced with or an
+// empty string if dep isn't replaced with a local directory. Relative
+// directories are resolved against the directory of the file containing the
+// replace directive so replaces from modfiles and go.work files in different
+// directories can be compared.
+func resolvedLocalPath(dep dependencies.Dependency) string {
+	if !isLocalReplace(dep) {
+		return ""
+	}
+
+	dir := dep.EffectiveVersion().Path
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(dep.Location().ReplaceFilePath()), dir)
+	}
+
+	return filepath.Clean(dir)
+}
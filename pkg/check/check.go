@@ -0,0 +1,166 @@
+// Package check compares the versions of a project's dependencies against the
+// versions other modules want them to be at. It runs the same comparison as
+// the gomodcheck command so other tools can use it without running the
+// command. Nothing is printed unless an output is set in the Options.
+package check
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/internal/engine"
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// Options configures a Checker. MatchDeps and MatchReplaces are the rules that
+// determine which dependencies are compared. The remaining fields are
+// optional.
+type Options struct {
+	// MatchDeps contains <package path>:<dep path> rules. The version of dep
+	// path required by the module providing package path must match the version
+	// the project uses.
+	MatchDeps []string
+
+	// MatchReplaces contains package paths whose module's replace directives
+	// the project must match.
+	MatchReplaces []string
+
+	// Aliases contains <alias path>:<canonical path> pairs of module paths that
+	// should be treated as the same dependency.
+	Aliases []string
+
+	// CanonicalizeRules contains <path regex>=<replacement> rules applied in
+	// order to module paths that aren't an alias.
+	CanonicalizeRules []string
+
+	// Dir is the directory to load packages from. If empty the current
+	// directory is used.
+	Dir string
+
+	// WorkFile is the go.work file to load the project with. Relative paths are
+	// resolved against Dir. If empty the file the go command detects is used.
+	WorkFile string
+
+	// ReachableOnly restricts comparisons to modules that provide a package in
+	// the transitive import graph of the loaded packages.
+	ReachableOnly bool
+
+	// SourceDirectOnly ignores wanted versions that come from indirect
+	// requirements of match-dep sources.
+	SourceDirectOnly bool
+
+	// SkipPrerelease ignores mismatches where either version is a pre-release
+	// or pseudo-version.
+	SkipPrerelease bool
+
+	// Tests and BuildFlags are passed through when loading packages.
+	Tests      bool
+	BuildFlags []string
+
+	// FormatPath returns the form of a file path to use in messages and in the
+	// versions of deps replaced with a local directory. Paths are used as is if
+	// it's nil.
+	FormatPath func(path string) string
+
+	// OnMismatch is called with each mismatch as soon as it's found, before
+	// Check returns. It's called from the goroutine running Check.
+	OnMismatch func(Mismatch)
+
+	// Log receives extra information about the run. It's discarded if nil.
+	Log io.Writer
+
+	// TraceDep is the path of a dep to describe each comparison step for.
+	// Steps are written to Trace.
+	TraceDep string
+	Trace    io.Writer
+}
+
+// Mismatch describes a project dependency whose effective version differs
+// from the version a rule wants it to be at.
+type Mismatch struct {
+	// DepPath is the canonical path of the dep that has mismatched versions.
+	DepPath string
+
+	// GotPath is the path the dep was found under in the project. It differs
+	// from DepPath if the project used an alias for the dep.
+	GotPath string
+
+	WantVersion string
+	GotVersion  string
+
+	GotLoc  dependencies.LocationTree
+	WantLoc dependencies.LocationTree
+
+	// WantSource describes where the wanted version came from, like the
+	// match-dep source package or the package whose replace was matched.
+	WantSource string
+
+	// IncompatibleMigration denotes that GotPath and DepPath are the
+	// +incompatible and module forms of the same major version of the dep.
+	IncompatibleMigration bool
+}
+
+// Checker loads a project's dependencies and compares them against the rules
+// it was created with. A Checker isn't safe for concurrent use.
+type Checker struct {
+	engine     *engine.Checker
+	onMismatch func(Mismatch)
+}
+
+// New returns a Checker for the given options. It returns an error if any of
+// the rules are invalid, like a malformed match-dep rule or a dep whose wanted
+// version is sourced from multiple packages.
+func New(opts Options) (*Checker, error) {
+	e, err := engine.New(engine.Options{
+		MatchDeps:         opts.MatchDeps,
+		MatchReplaces:     opts.MatchReplaces,
+		Aliases:           opts.Aliases,
+		CanonicalizeRules: opts.CanonicalizeRules,
+		Dir:               opts.Dir,
+		WorkFile:          opts.WorkFile,
+		ReachableOnly:     opts.ReachableOnly,
+		SourceDirectOnly:  opts.SourceDirectOnly,
+		SkipPrerelease:    opts.SkipPrerelease,
+		Tests:             opts.Tests,
+		BuildFlags:        opts.BuildFlags,
+		FormatPath:        opts.FormatPath,
+		Log:               opts.Log,
+		TraceDep:          opts.TraceDep,
+		Trace:             opts.Trace,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &Checker{engine: e, onMismatch: opts.OnMismatch}, nil
+}
+
+// Check loads the packages matching the patterns along with their
+// dependencies and returns every mismatch between the versions the rules want
+// and the versions the project uses. Anything loaded by a previous call is
+// discarded.
+func (c *Checker) Check(
+	ctx context.Context,
+	packagePatterns ...string,
+) ([]Mismatch, error) {
+	if err := c.engine.Load(ctx, packagePatterns...); err != nil {
+		return nil, errors.Wrap(err, "loading packages")
+	}
+
+	var res []Mismatch
+
+	err := c.engine.Compare(func(m engine.Mismatch) {
+		res = append(res, Mismatch(m))
+
+		if c.onMismatch != nil {
+			c.onMismatch(Mismatch(m))
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "comparing dependencies")
+	}
+
+	return res, nil
+}
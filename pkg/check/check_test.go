@@ -0,0 +1,219 @@
+package check_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alcionai/gomodcheck/pkg/check"
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// isolateGoEnv keeps settings from the environment running the tests from
+// changing how the go command loads the fixture modules.
+func isolateGoEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOPROXY", "off")
+}
+
+// moduleDir returns the name of the directory containing the modfile of loc.
+func moduleDir(loc dependencies.LocationTree) string {
+	return filepath.Base(filepath.Dir(loc.ModFilePath()))
+}
+
+func TestNewValidatesRules(t *testing.T) {
+	table := []struct {
+		name    string
+		opts    check.Options
+		wantErr string
+	}{
+		{
+			name: "Valid",
+			opts: check.Options{
+				MatchDeps:     []string{"example.com/a:example.com/x"},
+				MatchReplaces: []string{"example.com/b"},
+				Aliases:       []string{"example.com/y:example.com/x"},
+			},
+		},
+		{
+			name:    "MatchDepMissingSeparator",
+			opts:    check.Options{MatchDeps: []string{"example.com/a"}},
+			wantErr: "unexpected dep match input",
+		},
+		{
+			name:    "MatchDepEmptyPath",
+			opts:    check.Options{MatchDeps: []string{"example.com/a:"}},
+			wantErr: "empty package path in dep match input",
+		},
+		{
+			name: "DepSourcedFromMultiplePackages",
+			opts: check.Options{
+				MatchDeps: []string{
+					"example.com/a:example.com/x",
+					"example.com/b:example.com/x",
+				},
+			},
+			wantErr: "being sourced from multiple packages",
+		},
+		{
+			name: "AliasedDepSourcedFromMultiplePackages",
+			opts: check.Options{
+				MatchDeps: []string{
+					"example.com/a:example.com/x",
+					"example.com/b:example.com/y",
+				},
+				Aliases: []string{"example.com/y:example.com/x"},
+			},
+			wantErr: "being sourced from multiple packages",
+		},
+		{
+			name:    "AliasToItself",
+			opts:    check.Options{Aliases: []string{"example.com/x:example.com/x"}},
+			wantErr: "package aliased to itself",
+		},
+		{
+			name:    "InvalidCanonicalizeRegex",
+			opts:    check.Options{CanonicalizeRules: []string{"(=x"}},
+			wantErr: "canonicalize rule 0",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := check.New(test.opts)
+
+			if len(test.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("got error %v, want error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckReturnsMismatches(t *testing.T) {
+	isolateGoEnv(t)
+
+	otherDir, err := filepath.Abs(filepath.Join("testdata", "replaces", "other"))
+	if err != nil {
+		t.Fatalf("getting fixture path: %v", err)
+	}
+
+	table := []struct {
+		name       string
+		opts       check.Options
+		wantSource string
+	}{
+		{
+			name:       "MatchReplaces",
+			opts:       check.Options{MatchReplaces: []string{"example.com/dep"}},
+			wantSource: "replace in example.com/dep",
+		},
+		{
+			name: "MatchDeps",
+			opts: check.Options{
+				MatchDeps: []string{"example.com/dep:example.com/other"},
+			},
+			wantSource: "match-dep source example.com/dep",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			var streamed []check.Mismatch
+
+			opts := test.opts
+			opts.Dir = filepath.Join("testdata", "replaces", "proj")
+			opts.OnMismatch = func(m check.Mismatch) {
+				streamed = append(streamed, m)
+			}
+
+			checker, err := check.New(opts)
+			if err != nil {
+				t.Fatalf("creating checker: %v", err)
+			}
+
+			mismatches, err := checker.Check(context.Background(), "./...")
+			if err != nil {
+				t.Fatalf("checking: %v", err)
+			}
+
+			if len(mismatches) != 1 {
+				t.Fatalf("got %d mismatches, want 1: %+v", len(mismatches), mismatches)
+			}
+
+			if len(streamed) != len(mismatches) {
+				t.Errorf(
+					"OnMismatch got %d mismatches, want %d",
+					len(streamed),
+					len(mismatches),
+				)
+			}
+
+			m := mismatches[0]
+
+			if m.DepPath != "example.com/other" || m.GotPath != m.DepPath {
+				t.Errorf(
+					"got dep %s found as %s, want example.com/other",
+					m.DepPath,
+					m.GotPath,
+				)
+			}
+
+			if want := "example.com/other@v1.2.0"; m.WantVersion != want {
+				t.Errorf("got want version %s, want %s", m.WantVersion, want)
+			}
+
+			if want := "local path replacement " + otherDir; m.GotVersion != want {
+				t.Errorf("got version %s, want %s", m.GotVersion, want)
+			}
+
+			if m.WantSource != test.wantSource {
+				t.Errorf("got want source %s, want %s", m.WantSource, test.wantSource)
+			}
+
+			if m.GotLoc == nil || m.WantLoc == nil {
+				t.Fatalf("missing locations: got %v, want %v", m.GotLoc, m.WantLoc)
+			}
+
+			if got := moduleDir(m.GotLoc); got != "proj" {
+				t.Errorf("got version location in module dir %s, want proj", got)
+			}
+
+			if got := moduleDir(m.WantLoc); got != "dep" {
+				t.Errorf("want version location in module dir %s, want dep", got)
+			}
+		})
+	}
+}
+
+func TestCheckNoMismatches(t *testing.T) {
+	isolateGoEnv(t)
+
+	checker, err := check.New(check.Options{
+		Dir:       filepath.Join("testdata", "replaces", "proj"),
+		MatchDeps: []string{"example.com/other:example.com/dep"},
+	})
+	if err != nil {
+		t.Fatalf("creating checker: %v", err)
+	}
+
+	mismatches, err := checker.Check(context.Background(), "./...")
+	if err != nil {
+		t.Fatalf("checking: %v", err)
+	}
+
+	if len(mismatches) != 0 {
+		t.Fatalf("got mismatches %+v, want none", mismatches)
+	}
+}
@@ -0,0 +1,7 @@
+module example.com/dep
+
+go 1.21
+
+require example.com/other v1.0.0
+
+replace example.com/other => example.com/other v1.2.0
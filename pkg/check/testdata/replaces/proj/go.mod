@@ -0,0 +1,13 @@
+module example.com/proj
+
+go 1.21
+
+require (
+	example.com/dep v1.0.0
+	example.com/other v1.0.0
+)
+
+replace (
+	example.com/dep => ../dep
+	example.com/other => ../other
+)
@@ -0,0 +1,8 @@
+package main
+
+import (
+	_ "example.com/dep"
+	_ "example.com/other"
+)
+
+func main() {}
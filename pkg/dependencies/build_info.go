@@ -0,0 +1,73 @@
+package dependencies
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+)
+
+// develVersion is the version the go toolchain records in build info for
+// modules that were built from a local directory.
+const develVersion = "(devel)"
+
+func moduleVersion(mod *debug.Module) module.Version {
+	res := module.Version{Path: mod.Path, Version: mod.Version}
+
+	// Modfiles don't have a version for replacements with local directories so
+	// drop the placeholder to make the versions compare equal.
+	if res.Version == develVersion {
+		res.Version = ""
+	}
+
+	return res
+}
+
+// NewProjectDependenciesFromBinary creates a set of dependencies from the
+// module info embedded in a compiled go binary. Since the dependencies don't
+// come from a modfile their locations have no row or column info and
+// ParentPackage reports the main module of the binary.
+func NewProjectDependenciesFromBinary(
+	binaryPath string,
+) (PackageDependencies, error) {
+	info, err := buildinfo.ReadFile(binaryPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading binary build info")
+	}
+
+	res := &projectDependencies{
+		modulePath:         info.Main.Path,
+		goVersion:          strings.TrimPrefix(info.GoVersion, "go"),
+		allDependencies:    map[string]*dependency{},
+		directDependencies: map[string]*dependency{},
+		replacements:       map[string]*dependency{},
+	}
+
+	parent := fmt.Sprintf(
+		"%s (binary %s)",
+		moduleVersion(&info.Main).String(),
+		binaryPath,
+	)
+
+	for _, mod := range info.Deps {
+		dep := &dependency{
+			originalVersion:  moduleVersion(mod),
+			effectiveVersion: moduleVersion(mod),
+			location: &dependencyLocationTree{
+				parentModVersion: parent,
+			},
+		}
+
+		if mod.Replace != nil {
+			dep.effectiveVersion = moduleVersion(mod.Replace)
+			res.replacements[mod.Path] = dep
+		}
+
+		res.allDependencies[mod.Path] = dep
+	}
+
+	return res, nil
+}
@@ -0,0 +1,46 @@
+package dependencies
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// checkDuplicateReplaces returns an error if more than one of the replace
+// directives read from the file at filePath targets the same module and
+// version. Every directive is checked before any are applied so the result
+// doesn't depend on the order of the directives in the file or on whether the
+// module is required. The first duplicate in file order is reported.
+func checkDuplicateReplaces(filePath string, reps []*modfile.Replace) error {
+	seen := make(map[module.Version]*modfile.Replace, len(reps))
+
+	for _, rep := range reps {
+		first, ok := seen[rep.Old]
+		if !ok {
+			seen[rep.Old] = rep
+			continue
+		}
+
+		kind := "version-specific"
+		if len(rep.Old.Version) == 0 {
+			kind = "non-version-specific"
+		}
+
+		firstLoc := replaceLocation(first)
+		loc := replaceLocation(rep)
+
+		return errors.Errorf(
+			"multiple %s replace directives for module %s: in %s at line %d, "+
+				"col %d and line %d, col %d",
+			kind,
+			rep.Old.Path,
+			filePath,
+			firstLoc.Row,
+			firstLoc.Col,
+			loc.Row,
+			loc.Col,
+		)
+	}
+
+	return nil
+}
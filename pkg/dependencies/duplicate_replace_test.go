@@ -0,0 +1,141 @@
+package dependencies
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDuplicateReplaces(t *testing.T) {
+	const header = "module example.com/proj\n\n" +
+		"require example.com/foo v1.0.0\n\n"
+
+	table := []struct {
+		name     string
+		replaces []string
+
+		// wantErr is the expected error message without the file locations. If
+		// empty no error is expected.
+		wantErr string
+
+		// wantVersion is the expected effective version of example.com/foo if no
+		// error is expected.
+		wantVersion string
+	}{
+		{
+			name: "VersionSpecificSameTarget",
+			replaces: []string{
+				"example.com/foo v1.0.0 => example.com/bar v1.1.0",
+				"example.com/foo v1.0.0 => example.com/bar v1.1.0",
+			},
+			wantErr: "multiple version-specific replace directives for module " +
+				"example.com/foo",
+		},
+		{
+			name: "VersionSpecificDifferentTargets",
+			replaces: []string{
+				"example.com/foo v1.0.0 => example.com/bar v1.1.0",
+				"example.com/foo v1.0.0 => example.com/baz v1.2.0",
+			},
+			wantErr: "multiple version-specific replace directives for module " +
+				"example.com/foo",
+		},
+		{
+			name: "VersionSpecificDifferentTargetsReversed",
+			replaces: []string{
+				"example.com/foo v1.0.0 => example.com/baz v1.2.0",
+				"example.com/foo v1.0.0 => example.com/bar v1.1.0",
+			},
+			wantErr: "multiple version-specific replace directives for module " +
+				"example.com/foo",
+		},
+		{
+			name: "VersionSpecificForUnrequiredVersion",
+			replaces: []string{
+				"example.com/foo v0.9.0 => example.com/bar v1.1.0",
+				"example.com/foo v0.9.0 => example.com/baz v1.2.0",
+			},
+			wantErr: "multiple version-specific replace directives for module " +
+				"example.com/foo",
+		},
+		{
+			name: "Global",
+			replaces: []string{
+				"example.com/foo => example.com/bar v1.1.0",
+				"example.com/foo => example.com/baz v1.2.0",
+			},
+			wantErr: "multiple non-version-specific replace directives for " +
+				"module example.com/foo",
+		},
+		{
+			name: "GlobalReversed",
+			replaces: []string{
+				"example.com/foo => example.com/baz v1.2.0",
+				"example.com/foo => example.com/bar v1.1.0",
+			},
+			wantErr: "multiple non-version-specific replace directives for " +
+				"module example.com/foo",
+		},
+		{
+			name: "GlobalThenVersionSpecific",
+			replaces: []string{
+				"example.com/foo => example.com/baz v1.2.0",
+				"example.com/foo v1.0.0 => example.com/bar v1.1.0",
+			},
+			wantVersion: "example.com/bar@v1.1.0",
+		},
+		{
+			name: "VersionSpecificThenGlobal",
+			replaces: []string{
+				"example.com/foo v1.0.0 => example.com/bar v1.1.0",
+				"example.com/foo => example.com/baz v1.2.0",
+			},
+			wantVersion: "example.com/bar@v1.1.0",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			data := header + "replace " +
+				strings.Join(test.replaces, "\nreplace ") + "\n"
+
+			modFilePath := filepath.Join(t.TempDir(), "go.mod")
+
+			if err := os.WriteFile(modFilePath, []byte(data), 0o600); err != nil {
+				t.Fatalf("writing modfile: %v", err)
+			}
+
+			deps, err := NewProjectDependenciesFromModfile(nil, modFilePath)
+
+			if len(test.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				dep := deps.GetDep("example.com/foo")
+				if dep == nil {
+					t.Fatal("example.com/foo not found")
+				}
+
+				if got := dep.EffectiveVersion().String(); got != test.wantVersion {
+					t.Errorf("got effective version %s, want %s", got, test.wantVersion)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("got no error, want %q", test.wantErr)
+			}
+
+			// The replaces start on lines 5 and 6 after the module and require
+			// directives.
+			want := test.wantErr + ": in " + modFilePath +
+				" at line 5, col 1 and line 6, col 1"
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("got error %q, want it to contain %q", err.Error(), want)
+			}
+		})
+	}
+}
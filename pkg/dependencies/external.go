@@ -0,0 +1,57 @@
+package dependencies
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+)
+
+// ExternalPin is a module version pinned by a file that isn't a modfile, like
+// a build system's dependency manifest.
+type ExternalPin struct {
+	// Original is the module path and version the pin is for.
+	Original module.Version
+	// Effective is the module version that will be used for the pin. It differs
+	// from Original if the manifest replaces the module.
+	Effective module.Version
+	// Location is where the pin appears in the file.
+	Location FileLocation
+}
+
+// NewProjectDependenciesFromPins creates a set of dependencies from pins read
+// from the file at filePath. The dependencies have no parent module and are
+// all considered direct.
+func NewProjectDependenciesFromPins(
+	filePath string,
+	pins []ExternalPin,
+) (PackageDependencies, error) {
+	res := &projectDependencies{
+		allDependencies:    map[string]*dependency{},
+		directDependencies: map[string]*dependency{},
+		replacements:       map[string]*dependency{},
+	}
+
+	for _, pin := range pins {
+		if _, ok := res.allDependencies[pin.Original.Path]; ok {
+			return nil, errors.Errorf("duplicate dependency %s", pin.Original.Path)
+		}
+
+		dep := &dependency{
+			originalVersion:  pin.Original,
+			effectiveVersion: pin.Effective,
+			location: &dependencyLocationTree{
+				modFilePath: filePath,
+				original:    pin.Location,
+			},
+			direct: true,
+		}
+
+		res.allDependencies[pin.Original.Path] = dep
+		res.directDependencies[pin.Original.Path] = dep
+
+		if pin.Original != pin.Effective {
+			res.replacements[pin.Original.Path] = dep
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,96 @@
+package dependencies
+
+import (
+	"fmt"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// IneffectiveReplace describes a replace directive for a required module that
+// doesn't end up changing the module's effective version.
+type IneffectiveReplace struct {
+	Old module.Version
+	New module.Version
+
+	// Reason is a human-readable explanation of why the replace directive
+	// doesn't apply.
+	Reason string
+
+	// Location is the location of the replace directive. Its original location
+	// is the location of the replace directive.
+	Location LocationTree
+}
+
+func newIneffectiveReplace(
+	d *dependency,
+	old module.Version,
+	replacement module.Version,
+	loc FileLocation,
+	reason string,
+) IneffectiveReplace {
+	return IneffectiveReplace{
+		Old:    old,
+		New:    replacement,
+		Reason: reason,
+		Location: &dependencyLocationTree{
+			parentModVersion: d.location.parentModVersion,
+			modFilePath:      d.location.modFilePath,
+			original:         loc,
+		},
+	}
+}
+
+func replaceLocation(rep *modfile.Replace) FileLocation {
+	return FileLocation{
+		Row: rep.Syntax.Start.Line,
+		Col: rep.Syntax.Start.LineRune,
+	}
+}
+
+// versionMismatchReplace returns info about a version-specific replace
+// directive that targets a version of the module that isn't required.
+func versionMismatchReplace(
+	d *dependency,
+	rep *modfile.Replace,
+) *IneffectiveReplace {
+	res := newIneffectiveReplace(
+		d,
+		rep.Old,
+		rep.New,
+		replaceLocation(rep),
+		fmt.Sprintf(
+			"replaces version %s but version %s is required",
+			rep.Old.Version,
+			d.OriginalVersion().Version,
+		),
+	)
+
+	return &res
+}
+
+// supersededReplace returns info about a non-version-specific replace directive
+// that doesn't apply because a version-specific replace directive for the
+// module takes precedence. globalLoc and globalNew describe the
+// non-version-specific replace directive while targetLoc is the location of the
+// version-specific one.
+func supersededReplace(
+	d *dependency,
+	globalNew module.Version,
+	globalLoc FileLocation,
+	targetLoc FileLocation,
+) *IneffectiveReplace {
+	res := newIneffectiveReplace(
+		d,
+		module.Version{Path: d.OriginalVersion().Path},
+		globalNew,
+		globalLoc,
+		fmt.Sprintf(
+			"superseded by version-specific replace at line %d, col %d",
+			targetLoc.Row,
+			targetLoc.Col,
+		),
+	)
+
+	return &res
+}
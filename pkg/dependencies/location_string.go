@@ -0,0 +1,35 @@
+package dependencies
+
+import "fmt"
+
+// LocationString returns a description of where the given file location in
+// loc is. Locations without a row didn't come from a modfile and are described
+// as coming from build info instead. Locations without a parent module came
+// from an external manifest. formatPath is applied to file paths before
+// they're output.
+func LocationString(
+	loc LocationTree,
+	fileLoc FileLocation,
+	formatPath func(string) string,
+) string {
+	if fileLoc.Row == 0 {
+		return "build info for module " + loc.ParentPackage()
+	}
+
+	if len(loc.ParentPackage()) == 0 {
+		return fmt.Sprintf(
+			"manifest %s line %d, col %d",
+			formatPath(loc.ModFilePath()),
+			fileLoc.Row,
+			fileLoc.Col,
+		)
+	}
+
+	return fmt.Sprintf(
+		"modfile %s for module %s line %d, col %d",
+		formatPath(loc.ModFilePath()),
+		loc.ParentPackage(),
+		fileLoc.Row,
+		fileLoc.Col,
+	)
+}
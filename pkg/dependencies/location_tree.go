@@ -2,8 +2,18 @@ package dependencies
 
 type LocationTree interface {
 	ParentPackage() string
+	// ModFilePath returns the path of the modfile this location is in or an
+	// empty string if the location isn't from a modfile.
+	ModFilePath() string
 	OriginalLocation() FileLocation
 	EffectiveLocation() FileLocation
+	// ReplaceLocation returns the location of the replace directive that
+	// updated this dependency and true, or false if no replace directive applied.
+	ReplaceLocation() (FileLocation, bool)
+	// ReplaceFilePath returns the path of the file containing the replace
+	// directive that updated this dependency. This differs from ModFilePath if
+	// the replace directive came from a go.work file.
+	ReplaceFilePath() string
 
 	Ancestor() LocationTree
 }
@@ -18,6 +28,10 @@ type dependencyLocationTree struct {
 	// including the package path and version number.
 	parentModVersion string
 
+	// modFilePath is the path of the parent gomodfile as it was passed when
+	// loading the dependencies.
+	modFilePath string
+
 	// original holds the line number and column inthe line in the parent
 	// gomodfile this dependency was originally added at.
 	original FileLocation
@@ -26,6 +40,10 @@ type dependencyLocationTree struct {
 	// gomodfile this dependency was was replaced at.
 	replace FileLocation
 
+	// replaceModFilePath is the path of the file the replace directive is in if
+	// it's not the parent gomodfile.
+	replaceModFilePath string
+
 	// ancestor denotes a previous file location that may help add more context.
 	// For example, if a replace directive is included because of another replace
 	// directive this can help track it down by showing the full lineage of
@@ -37,18 +55,34 @@ func (d dependencyLocationTree) ParentPackage() string {
 	return d.parentModVersion
 }
 
+func (d dependencyLocationTree) ModFilePath() string {
+	return d.modFilePath
+}
+
 func (d dependencyLocationTree) OriginalLocation() FileLocation {
 	return d.original
 }
 
 func (d dependencyLocationTree) EffectiveLocation() FileLocation {
-	if d.replace.Row != 0 {
-		return d.replace
+	if rep, ok := d.ReplaceLocation(); ok {
+		return rep
 	}
 
 	return d.OriginalLocation()
 }
 
+func (d dependencyLocationTree) ReplaceLocation() (FileLocation, bool) {
+	return d.replace, d.replace.Row != 0
+}
+
+func (d dependencyLocationTree) ReplaceFilePath() string {
+	if len(d.replaceModFilePath) > 0 {
+		return d.replaceModFilePath
+	}
+
+	return d.modFilePath
+}
+
 func (d dependencyLocationTree) Ancestor() LocationTree {
 	return d.ancestor
 }
@@ -1,7 +1,9 @@
 package dependencies
 
 import (
+	"fmt"
 	"os"
+	"sort"
 
 	"github.com/pkg/errors"
 	"golang.org/x/mod/modfile"
@@ -11,14 +13,42 @@ import (
 type PackageDependencies interface {
 	Replacements() []Dependency
 	GetDep(packagePath string) Dependency
+	// AllDependencies returns every dependency in the set sorted by package
+	// path.
+	AllDependencies() []Dependency
+	// ModulePath returns the path of the module the dependencies were read for.
+	ModulePath() string
+	// ModFilePath returns the path of the modfile the dependencies were read
+	// from or an empty string if they weren't read from a modfile.
+	ModFilePath() string
+	// ModuleLocation returns the location of the module directive in the
+	// modfile or an empty location if they weren't read from a modfile.
+	ModuleLocation() FileLocation
+	// GoVersion returns the go version the module declares or an empty string
+	// if it doesn't declare one.
+	GoVersion() string
+	// GoVersionLocation returns the location of the go directive in the modfile
+	// or an empty location if the module doesn't declare a go version.
+	GoVersionLocation() FileLocation
+	// IneffectiveReplaces returns the replace directives for required modules
+	// that never apply, either because they target a version that isn't
+	// required or because another replace directive takes precedence.
+	IneffectiveReplaces() []IneffectiveReplace
+	// UnrequiredReplaces returns the replace directives for modules that aren't
+	// required at all.
+	UnrequiredReplaces() []IneffectiveReplace
+	// UnhandledDirectives returns the top-level modfile directives that weren't
+	// used when determining the effective versions of dependencies.
+	UnhandledDirectives() []UnhandledDirective
 }
 
 type Dependency interface {
-	// TODO(ashmrtn): Add another function that points to the line the replacement
-	// was done at.
 	OriginalVersion() module.Version
 	EffectiveVersion() module.Version
 	Location() LocationTree
+	// Direct returns true if the dependency is required without an indirect
+	// comment in the modfile it was read from.
+	Direct() bool
 }
 
 type dependency struct {
@@ -27,6 +57,7 @@ type dependency struct {
 
 	location *dependencyLocationTree
 
+	direct        bool
 	globalReplace bool
 }
 
@@ -38,6 +69,10 @@ func (d dependency) EffectiveVersion() module.Version {
 	return d.effectiveVersion
 }
 
+func (d dependency) Direct() bool {
+	return d.direct
+}
+
 func (d dependency) Location() LocationTree {
 	if d.location == nil {
 		return nil
@@ -46,7 +81,34 @@ func (d dependency) Location() LocationTree {
 	return d.location
 }
 
-func (d *dependency) maybeUpdate(rep *modfile.Replace) (bool, error) {
+// replaced returns true if a replace directive has been applied to d.
+func (d dependency) replaced() bool {
+	_, ok := d.location.ReplaceLocation()
+	return ok
+}
+
+// replaceConflictLocations describes where the replace directive already
+// applied to d and the conflicting replace directive rep are.
+func (d dependency) replaceConflictLocations(rep *modfile.Replace) string {
+	conflict := replaceLocation(rep)
+
+	return fmt.Sprintf(
+		"in %s at line %d, col %d and line %d, col %d",
+		d.location.ReplaceFilePath(),
+		d.location.replace.Row,
+		d.location.replace.Col,
+		conflict.Row,
+		conflict.Col,
+	)
+}
+
+// maybeUpdate applies the replace directive to the dependency if it targets
+// the dependency's version. If the replace directive doesn't apply, or causes a
+// previously applied replace directive to no longer apply, info about the
+// ineffective replace directive is also returned.
+func (d *dependency) maybeUpdate(
+	rep *modfile.Replace,
+) (bool, *IneffectiveReplace, error) {
 	// Handle targetted replace directives. Either:
 	//   * The replace directive isn't targetting this version so there's nothing
 	//     to do
@@ -57,44 +119,64 @@ func (d *dependency) maybeUpdate(rep *modfile.Replace) (bool, error) {
 	if len(rep.Old.Version) > 0 {
 		// Replace statment for a different module version, nothing to do.
 		if d.OriginalVersion().Version != rep.Old.Version {
-			return false, nil
+			return false, versionMismatchReplace(d, rep), nil
 		}
 
-		if d.OriginalVersion() != d.EffectiveVersion() && !d.globalReplace {
-			return false, errors.Errorf(
-				"multiple version-specific replace directives for module %s",
+		if d.replaced() && !d.globalReplace {
+			return false, nil, errors.Errorf(
+				"multiple version-specific replace directives for module %s: %s",
 				d.OriginalVersion().Path,
+				d.replaceConflictLocations(rep),
+			)
+		}
+
+		var superseded *IneffectiveReplace
+
+		// A global replace directive earlier in the file was applied but it's
+		// overridden by this one.
+		if d.globalReplace {
+			superseded = supersededReplace(
+				d,
+				d.effectiveVersion,
+				d.location.replace,
+				replaceLocation(rep),
 			)
 		}
 
 		d.effectiveVersion = rep.New
-		d.location.replace.Row = rep.Syntax.Start.Line
-		d.location.replace.Col = rep.Syntax.Start.LineRune
+		d.location.replace = replaceLocation(rep)
 		d.globalReplace = false
 
-		return true, nil
+		return true, superseded, nil
 	}
 
-	// Remainder of function deals with untargetted replace directives.
-	if d.EffectiveVersion().Version != d.OriginalVersion().Version {
+	// Remainder of function deals with untargetted replace directives. Whether
+	// the dep was replaced needs to be checked with the replace location because
+	// the replacement may have the same version string as the original.
+	if d.replaced() {
 		if d.globalReplace {
-			return false, errors.Errorf(
-				"multiple non-version-specific replace directives for module %s",
+			return false, nil, errors.Errorf(
+				"multiple non-version-specific replace directives for module %s: %s",
 				d.OriginalVersion().Path,
+				d.replaceConflictLocations(rep),
 			)
 		}
 
 		// The module's already had it's version updated by a targetted replace
 		// directive.
-		return false, nil
+		return false, supersededReplace(
+			d,
+			rep.New,
+			replaceLocation(rep),
+			d.location.replace,
+		), nil
 	}
 
 	d.effectiveVersion = rep.New
-	d.location.replace.Row = rep.Syntax.Start.Line
-	d.location.replace.Col = rep.Syntax.Start.LineRune
+	d.location.replace = replaceLocation(rep)
 	d.globalReplace = true
 
-	return true, nil
+	return true, nil, nil
 }
 
 func readModFile(path string) (*modfile.File, error) {
@@ -103,7 +185,11 @@ func readModFile(path string) (*modfile.File, error) {
 		return nil, errors.Wrap(err, "reading mod file")
 	}
 
-	f, err := modfile.Parse(path, mod, nil)
+	return parseModFile(path, mod)
+}
+
+func parseModFile(path string, data []byte) (*modfile.File, error) {
+	f, err := modfile.Parse(path, data, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing mod file")
 	}
@@ -120,12 +206,55 @@ func NewProjectDependenciesFromModfile(
 		return nil, errors.WithStack(err)
 	}
 
+	return newProjectDependencies(parentModDecl, modFilePath, modFile)
+}
+
+// NewProjectDependenciesFromModfileData is like
+// NewProjectDependenciesFromModfile but parses the given modfile contents
+// instead of reading them from modFilePath. modFilePath is only used when
+// reporting locations. This allows loading modfiles that aren't on disk, like
+// older revisions from version control.
+func NewProjectDependenciesFromModfileData(
+	parentModDecl Dependency,
+	modFilePath string,
+	data []byte,
+) (PackageDependencies, error) {
+	modFile, err := parseModFile(modFilePath, data)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return newProjectDependencies(parentModDecl, modFilePath, modFile)
+}
+
+func newProjectDependencies(
+	parentModDecl Dependency,
+	modFilePath string,
+	modFile *modfile.File,
+) (PackageDependencies, error) {
 	res := &projectDependencies{
+		modFilePath:   modFilePath,
+		moduleVersion: modFile.Module.Mod.String(),
+		modulePath:    modFile.Module.Mod.Path,
+		moduleLocation: FileLocation{
+			Row: modFile.Module.Syntax.Start.Line,
+			Col: modFile.Module.Syntax.Start.LineRune,
+		},
 		allDependencies:    map[string]*dependency{},
 		directDependencies: map[string]*dependency{},
 		replacements:       map[string]*dependency{},
 	}
 
+	if modFile.Go != nil {
+		res.goVersion = modFile.Go.Version
+		res.goVersionLocation = FileLocation{
+			Row: modFile.Go.Syntax.Start.Line,
+			Col: modFile.Go.Syntax.Start.LineRune,
+		}
+	}
+
+	res.unhandledDirectives = findUnhandledDirectives(modFile)
+
 	for _, req := range modFile.Require {
 		if _, ok := res.allDependencies[req.Mod.Path]; ok {
 			return nil, errors.Errorf("duplicate dependency %s", req.Mod.Path)
@@ -133,6 +262,7 @@ func NewProjectDependenciesFromModfile(
 
 		loc := &dependencyLocationTree{
 			parentModVersion: modFile.Module.Mod.String(),
+			modFilePath:      modFilePath,
 			original: FileLocation{
 				Row: req.Syntax.Start.Line,
 				Col: req.Syntax.Start.LineRune,
@@ -147,6 +277,7 @@ func NewProjectDependenciesFromModfile(
 			originalVersion:  req.Mod,
 			effectiveVersion: req.Mod,
 			location:         loc,
+			direct:           !req.Indirect,
 		}
 
 		res.allDependencies[req.Mod.Path] = dep
@@ -156,6 +287,10 @@ func NewProjectDependenciesFromModfile(
 		}
 	}
 
+	if err := checkDuplicateReplaces(modFilePath, modFile.Replace); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	for _, rep := range modFile.Replace {
 		if err := res.updateEffectiveVersion(rep); err != nil {
 			return nil, errors.WithStack(err)
@@ -166,6 +301,27 @@ func NewProjectDependenciesFromModfile(
 }
 
 type projectDependencies struct {
+	// modFilePath is the path of the modfile the dependencies were read from.
+	// It's empty if they weren't read from a modfile.
+	modFilePath string
+
+	// moduleVersion is the module path and version the dependencies were read
+	// for.
+	moduleVersion string
+
+	// modulePath is the path of the module these dependencies are for.
+	modulePath string
+
+	// moduleLocation is the location of the module directive in the modfile.
+	moduleLocation FileLocation
+
+	// goVersion is the version in the go directive of the module or empty if
+	// there was no go directive.
+	goVersion string
+
+	// goVersionLocation is the location of the go directive in the modfile.
+	goVersionLocation FileLocation
+
 	// replacements contains package path -> dep info for all dependency that have
 	// been updated by replace directives.
 	replacements map[string]*dependency
@@ -177,6 +333,50 @@ type projectDependencies struct {
 	// allDependencies contains the package path -> dep info for every dependency
 	// in this package.
 	allDependencies map[string]*dependency
+
+	// ineffectiveReplaces contains info about replace directives for required
+	// modules that don't change the effective version of the module.
+	ineffectiveReplaces []IneffectiveReplace
+
+	// unhandledDirectives contains info about top-level modfile directives that
+	// weren't used when reading the dependencies.
+	unhandledDirectives []UnhandledDirective
+
+	// unrequiredReplaces contains info about replace directives for modules that
+	// aren't required.
+	unrequiredReplaces []IneffectiveReplace
+}
+
+func (p projectDependencies) ModulePath() string {
+	return p.modulePath
+}
+
+func (p projectDependencies) ModFilePath() string {
+	return p.modFilePath
+}
+
+func (p projectDependencies) ModuleLocation() FileLocation {
+	return p.moduleLocation
+}
+
+func (p projectDependencies) GoVersion() string {
+	return p.goVersion
+}
+
+func (p projectDependencies) GoVersionLocation() FileLocation {
+	return p.goVersionLocation
+}
+
+func (p projectDependencies) IneffectiveReplaces() []IneffectiveReplace {
+	return p.ineffectiveReplaces
+}
+
+func (p projectDependencies) UnrequiredReplaces() []IneffectiveReplace {
+	return p.unrequiredReplaces
+}
+
+func (p projectDependencies) UnhandledDirectives() []UnhandledDirective {
+	return p.unhandledDirectives
 }
 
 func (p projectDependencies) GetDep(packagePath string) Dependency {
@@ -189,6 +389,20 @@ func (p projectDependencies) GetDep(packagePath string) Dependency {
 	return nil
 }
 
+func (p projectDependencies) AllDependencies() []Dependency {
+	res := make([]Dependency, 0, len(p.allDependencies))
+
+	for _, dep := range p.allDependencies {
+		res = append(res, dep)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].OriginalVersion().Path < res[j].OriginalVersion().Path
+	})
+
+	return res
+}
+
 func (p projectDependencies) Replacements() []Dependency {
 	res := make([]Dependency, 0, len(p.replacements))
 
@@ -207,14 +421,35 @@ func (p *projectDependencies) updateEffectiveVersion(
 	dep, ok := p.allDependencies[repPath]
 	if !ok {
 		// We don't have this dependency at all so there's nothing to update.
+		p.unrequiredReplaces = append(
+			p.unrequiredReplaces,
+			IneffectiveReplace{
+				Old:    rep.Old,
+				New:    rep.New,
+				Reason: "module isn't required",
+				Location: &dependencyLocationTree{
+					parentModVersion: p.moduleVersion,
+					modFilePath:      p.modFilePath,
+					original:         replaceLocation(rep),
+				},
+			},
+		)
+
 		return nil
 	}
 
-	if updated, err := dep.maybeUpdate(rep); err != nil {
+	updated, ineffective, err := dep.maybeUpdate(rep)
+	if err != nil {
 		return errors.WithStack(err)
-	} else if updated {
+	}
+
+	if updated {
 		p.replacements[repPath] = dep
 	}
 
+	if ineffective != nil {
+		p.ineffectiveReplaces = append(p.ineffectiveReplaces, *ineffective)
+	}
+
 	return nil
 }
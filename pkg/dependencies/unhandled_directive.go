@@ -0,0 +1,65 @@
+package dependencies
+
+import (
+	"golang.org/x/mod/modfile"
+)
+
+// handledDirectives contains the modfile directives that are used when
+// determining the effective versions of dependencies.
+var handledDirectives = map[string]struct{}{
+	"module":  {},
+	"go":      {},
+	"require": {},
+	"replace": {},
+}
+
+// UnhandledDirective describes a top-level modfile directive that isn't used
+// when determining the effective versions of dependencies. It may affect the
+// build in ways that aren't reflected in the dependency info.
+type UnhandledDirective struct {
+	// Kind is the directive's keyword, like exclude or toolchain.
+	Kind string
+
+	// Location is where the directive, or the block containing it, starts.
+	Location FileLocation
+}
+
+// findUnhandledDirectives returns every top-level directive in f that isn't
+// in handledDirectives. Blocks are reported once at the start of the block.
+func findUnhandledDirectives(f *modfile.File) []UnhandledDirective {
+	var res []UnhandledDirective
+
+	for _, stmt := range f.Syntax.Stmt {
+		var tokens []string
+
+		switch stmt := stmt.(type) {
+		case *modfile.Line:
+			tokens = stmt.Token
+		case *modfile.LineBlock:
+			tokens = stmt.Token
+		}
+
+		if len(tokens) == 0 {
+			continue
+		}
+
+		if _, ok := handledDirectives[tokens[0]]; ok {
+			continue
+		}
+
+		start, _ := stmt.Span()
+
+		res = append(
+			res,
+			UnhandledDirective{
+				Kind: tokens[0],
+				Location: FileLocation{
+					Row: start.Line,
+					Col: start.LineRune,
+				},
+			},
+		)
+	}
+
+	return res
+}
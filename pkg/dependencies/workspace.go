@@ -0,0 +1,111 @@
+package dependencies
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+)
+
+func readWorkFile(path string) (*modfile.WorkFile, error) {
+	work, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading work file")
+	}
+
+	f, err := modfile.ParseWork(path, work, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing work file")
+	}
+
+	return f, nil
+}
+
+// Workspace contains the info from a go.work file needed to determine the
+// effective versions of dependencies for modules in the workspace.
+type Workspace struct {
+	filePath string
+	modFiles []string
+	replaces []*modfile.Replace
+}
+
+// ReadWorkspace parses the go.work file at the given path.
+func ReadWorkspace(workFilePath string) (*Workspace, error) {
+	workFile, err := readWorkFile(workFilePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	workDir, err := filepath.Abs(filepath.Dir(workFilePath))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting work file directory")
+	}
+
+	err = checkDuplicateReplaces(workFilePath, workFile.Replace)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	res := &Workspace{
+		filePath: workFilePath,
+		modFiles: make([]string, 0, len(workFile.Use)),
+		replaces: workFile.Replace,
+	}
+
+	for _, use := range workFile.Use {
+		modDir := use.Path
+		if !filepath.IsAbs(modDir) {
+			modDir = filepath.Join(workDir, modDir)
+		}
+
+		res.modFiles = append(res.modFiles, filepath.Join(modDir, "go.mod"))
+	}
+
+	return res, nil
+}
+
+// ModFiles returns the paths of the gomodfiles for every module referenced by
+// a use directive in the workspace. Relative use paths are resolved against
+// the directory containing the go.work file.
+func (w Workspace) ModFiles() []string {
+	return w.modFiles
+}
+
+// ApplyReplaces updates the effective versions of deps using the replace
+// directives in the workspace. Workspace replace directives take precedence
+// over those in the module's modfile and version-specific replace directives
+// take precedence over ones that apply to all versions of a module.
+func (w Workspace) ApplyReplaces(deps PackageDependencies) error {
+	p, ok := deps.(*projectDependencies)
+	if !ok {
+		return errors.Errorf("unsupported dependency set type %T", deps)
+	}
+
+	// Two passes so that version-specific replaces are applied last and win.
+	for _, versionSpecific := range []bool{false, true} {
+		for _, rep := range w.replaces {
+			if (len(rep.Old.Version) > 0) != versionSpecific {
+				continue
+			}
+
+			dep, ok := p.allDependencies[rep.Old.Path]
+			if !ok {
+				continue
+			}
+
+			if versionSpecific &&
+				dep.OriginalVersion().Version != rep.Old.Version {
+				continue
+			}
+
+			dep.effectiveVersion = rep.New
+			dep.location.replace = replaceLocation(rep)
+			dep.location.replaceModFilePath = w.filePath
+			dep.globalReplace = !versionSpecific
+			p.replacements[rep.Old.Path] = dep
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// FormatBazel is the format name for files containing go_repository rules,
+// like those generated by gazelle.
+const FormatBazel = "bazel"
+
+var bazelAttrRegex = regexp.MustCompile(`^\s*(\w+)\s*=\s*"([^"]*)"`)
+
+// bazelParser reads go_repository rules. Each rule is expected to start on its
+// own line and have one attribute per line, which is how gazelle formats them.
+type bazelParser struct{}
+
+func (bazelParser) Parse(data []byte) ([]dependencies.ExternalPin, error) {
+	var (
+		res     []dependencies.ExternalPin
+		inRule  bool
+		attrs   map[string]string
+		ruleLoc dependencies.FileLocation
+		lineNum int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNum++
+
+		if !inRule {
+			idx := strings.Index(line, "go_repository(")
+			if idx < 0 || strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+
+			inRule = true
+			attrs = map[string]string{}
+			ruleLoc = dependencies.FileLocation{Row: lineNum, Col: idx + 1}
+
+			continue
+		}
+
+		if strings.TrimSpace(line) == ")" {
+			inRule = false
+
+			pin, err := bazelPin(attrs, ruleLoc)
+			if err != nil {
+				return nil, errors.Wrapf(err, "go_repository at line %d", ruleLoc.Row)
+			}
+
+			if pin != nil {
+				res = append(res, *pin)
+			}
+
+			continue
+		}
+
+		if match := bazelAttrRegex.FindStringSubmatch(line); match != nil {
+			attrs[match[1]] = match[2]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading bazel manifest")
+	}
+
+	if inRule {
+		return nil, errors.Errorf(
+			"unterminated go_repository at line %d",
+			ruleLoc.Row,
+		)
+	}
+
+	return res, nil
+}
+
+// bazelPin creates a pin from the attributes of a go_repository rule. Rules
+// that pin a commit instead of a module version can't be compared and are
+// skipped.
+func bazelPin(
+	attrs map[string]string,
+	loc dependencies.FileLocation,
+) (*dependencies.ExternalPin, error) {
+	path := attrs["importpath"]
+	if len(path) == 0 {
+		return nil, errors.New("missing importpath")
+	}
+
+	version, ok := attrs["version"]
+	if !ok {
+		return nil, nil
+	}
+
+	res := &dependencies.ExternalPin{
+		Original:  module.Version{Path: path, Version: version},
+		Effective: module.Version{Path: path, Version: version},
+		Location:  loc,
+	}
+
+	// gazelle sets version to the version of the replacement module.
+	if replace := attrs["replace"]; len(replace) > 0 {
+		res.Effective.Path = replace
+	}
+
+	return res, nil
+}
@@ -0,0 +1,59 @@
+// Package manifest reads module versions pinned by dependency manifests from
+// other build systems so they can be compared against modfiles.
+package manifest
+
+import (
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/alcionai/gomodcheck/pkg/dependencies"
+)
+
+// Parser extracts pinned module versions from the contents of a manifest.
+type Parser interface {
+	Parse(data []byte) ([]dependencies.ExternalPin, error)
+}
+
+var parsers = map[string]Parser{
+	FormatBazel: bazelParser{},
+}
+
+// Formats returns the names of the supported manifest formats.
+func Formats() []string {
+	res := make([]string, 0, len(parsers))
+
+	for format := range parsers {
+		res = append(res, format)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// Load reads the manifest at path using the parser for the given format.
+func Load(
+	format string,
+	path string,
+) (dependencies.PackageDependencies, error) {
+	parser, ok := parsers[format]
+	if !ok {
+		return nil, errors.Errorf("unsupported manifest format: %s", format)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading manifest")
+	}
+
+	pins, err := parser.Parse(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing manifest")
+	}
+
+	deps, err := dependencies.NewProjectDependenciesFromPins(path, pins)
+
+	return deps, errors.Wrap(err, "creating manifest dependencies")
+}